@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"cartero/internal/config"
+	"cartero/internal/platforms"
+	"cartero/internal/server/feed/handler"
+
+	"github.com/bluesky-social/indigo/api/atproto"
+	"github.com/bluesky-social/indigo/api/bsky"
+	"github.com/bluesky-social/indigo/lex/util"
+	"github.com/bluesky-social/indigo/xrpc"
+)
+
+// publishFeeds writes an app.bsky.feed.generator record for every feed of
+// every feed target, so Bluesky lists the feeds and asks this server for
+// their posts. Records are keyed by feed name and replaced on each run.
+func publishFeeds(ctx context.Context, cfg *config.Config, platform *platforms.BlueskyPlatform) error {
+	if platform == nil {
+		return fmt.Errorf("publishing feeds needs the bluesky platform")
+	}
+
+	names := make([]string, 0, len(cfg.Targets))
+	for name, target := range cfg.Targets {
+		if target.Type == "feed" && target.Enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	published := 0
+	for _, name := range names {
+		gen := cfg.Targets[name].Settings.FeedGenerator
+		for _, feed := range gen.Feeds {
+			record := &bsky.FeedGenerator{
+				LexiconTypeID: handler.GeneratorCollection,
+				Did:           gen.ServiceDID,
+				DisplayName:   feed.DisplayName,
+				CreatedAt:     time.Now().UTC().Format(time.RFC3339),
+			}
+			if record.DisplayName == "" {
+				record.DisplayName = feed.Name
+			}
+			if feed.Description != "" {
+				record.Description = &feed.Description
+			}
+
+			var out *atproto.RepoPutRecord_Output
+			err := platform.Do(ctx, func(c *xrpc.Client) error {
+				var err error
+				out, err = atproto.RepoPutRecord(ctx, c, &atproto.RepoPutRecord_Input{
+					Collection: handler.GeneratorCollection,
+					Repo:       c.Auth.Did,
+					Rkey:       feed.Name,
+					Record:     &util.LexiconTypeDecoder{Val: record},
+				})
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("failed to publish feed %s of %s: %w", feed.Name, name, err)
+			}
+
+			fmt.Printf("%s\t%s\t%s\n", name, feed.Name, out.Uri)
+			published++
+		}
+	}
+
+	fmt.Printf("Published %d feeds\n", published)
+	return nil
+}
@@ -8,16 +8,25 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"sort"
+	"strings"
 	"syscall"
 	"time"
 
 	"cartero"
+	"cartero/internal/components"
 	"cartero/internal/core"
 	"cartero/internal/state"
+	"cartero/internal/storage"
 )
 
 var (
 	configPath = flag.String("config", "config.toml", "Path to configuration file")
+	printChain = flag.Bool("print-chain", false, "Print the resolved processor chain order and exit")
+	deadLetter = flag.Bool("dead-letters", false, "List dead-lettered deliveries and exit")
+	requeue    = flag.String("requeue", "", "Requeue dead-lettered deliveries for an item ID (or \"all\") and exit")
+	requeueFor = flag.String("requeue-target", "", "Restrict -requeue to a single target")
+	pubFeeds   = flag.Bool("publish-feeds", false, "Publish the Bluesky feed generator records of feed targets and exit")
 )
 
 func main() {
@@ -45,6 +54,17 @@ func run(ctx context.Context) error {
 	fmt.Printf("Loading configuration from: %s\n", *configPath)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
 	appState := state.New(logger, cartero.EmbeddedScripts)
+
+	// The chain order only depends on the config, so it is printed without
+	// connecting to anything.
+	if *printChain {
+		if err := appState.LoadChains(ctx, *configPath); err != nil {
+			return fmt.Errorf("failed to resolve processor chains: %w", err)
+		}
+		printChainOrder(appState)
+		return nil
+	}
+
 	if err := appState.Initialize(ctx, *configPath); err != nil {
 		return fmt.Errorf("failed to initialize state: %w", err)
 	}
@@ -72,11 +92,35 @@ func run(ctx context.Context) error {
 		return st.Close(shutdownCtx)
 	}
 
+	if *deadLetter || *requeue != "" {
+		if err := manageOutbox(ctx, st.Outbox()); err != nil {
+			_ = shutdownFn()
+			return err
+		}
+		return shutdownFn()
+	}
+
+	if *pubFeeds {
+		platform := registry.Get(components.PlatformComponentName).(*components.PlatformComponent).Bluesky()
+		if err := publishFeeds(ctx, cfg, platform); err != nil {
+			_ = shutdownFn()
+			return err
+		}
+		return shutdownFn()
+	}
+
+	var updates *core.Updates
+	if cfg.Updates.Enabled {
+		updates = core.NewUpdates(pipeline, appState, cfg.Updates)
+	}
+
 	bot := core.NewBot(core.BotConfig{
 		Name:       cfg.Bot.Name,
 		Pipeline:   pipeline,
 		Filters:    appState.GetFilterChain(),
-		Targets:    pipeline.AllTargets(),
+		Chains:     appState.GetTargetFilterChains(),
+		Outbox:     core.NewOutbox(pipeline, appState.GetTargetFilterChains(), appState, cfg.Outbox),
+		Updates:    updates,
 		Interval:   interval,
 		RunOnce:    cfg.Bot.RunOnce,
 		ShutdownFn: shutdownFn,
@@ -111,3 +155,42 @@ func run(ctx context.Context) error {
 	fmt.Println("Bot stopped successfully")
 	return nil
 }
+
+func printChainOrder(appState *state.State) {
+	fmt.Printf("shared: %s\n", strings.Join(appState.GetFilterChain().Order(), " -> "))
+
+	chains := appState.GetTargetFilterChains()
+	targets := make([]string, 0, len(chains))
+	for name := range chains {
+		targets = append(targets, name)
+	}
+	sort.Strings(targets)
+	for _, name := range targets {
+		fmt.Printf("%s: %s\n", name, strings.Join(chains[name].Order(), " -> "))
+	}
+}
+
+func manageOutbox(ctx context.Context, outbox storage.OutboxStore) error {
+	if *requeue != "" {
+		itemID := *requeue
+		if itemID == "all" {
+			itemID = ""
+		}
+		n, err := outbox.Requeue(ctx, itemID, *requeueFor)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Requeued %d deliveries\n", n)
+		return nil
+	}
+
+	entries, err := outbox.ListDead(ctx, 100)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		fmt.Printf("%s\t%s\tattempts=%d\tupdated=%s\t%s\n", e.ItemID, e.Target, e.Attempts, e.UpdatedAt.Format(time.RFC3339), e.LastError)
+	}
+	fmt.Printf("%d dead-lettered deliveries\n", len(entries))
+	return nil
+}
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS publish_outbox (
+    item_id         TEXT NOT NULL REFERENCES feed_entries(id) ON DELETE CASCADE,
+    target          TEXT NOT NULL,
+    payload         JSONB NOT NULL,
+    attempts        INT NOT NULL DEFAULT 0,
+    status          TEXT NOT NULL DEFAULT 'pending',
+    last_error      TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at      TIMESTAMPTZ DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (item_id, target)
+);
+
+-- For the worker picking up due deliveries
+CREATE INDEX IF NOT EXISTS idx_publish_outbox_due
+    ON publish_outbox(next_attempt_at) WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_publish_outbox_due;
+DROP TABLE IF EXISTS publish_outbox;
+-- +goose StatementEnd
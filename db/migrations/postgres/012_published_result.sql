@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Remote identifiers returned by the target (message IDs, post URIs, ...)
+ALTER TABLE published ADD COLUMN IF NOT EXISTS result JSONB NOT NULL DEFAULT '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE published DROP COLUMN IF EXISTS result;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Source name the entry was gathered from, used to re-check it upstream
+ALTER TABLE feed_entries ADD COLUMN IF NOT EXISTS route TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_published_published_at
+    ON published(published_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_published_published_at;
+ALTER TABLE feed_entries DROP COLUMN IF EXISTS route;
+-- +goose StatementEnd
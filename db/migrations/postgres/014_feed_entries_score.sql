@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Ranking score of the entry, used to order custom feeds
+ALTER TABLE feed_entries ADD COLUMN IF NOT EXISTS score DOUBLE PRECISION;
+
+CREATE INDEX IF NOT EXISTS idx_published_target_published_at
+    ON published(target, published_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_published_target_published_at;
+ALTER TABLE feed_entries DROP COLUMN IF EXISTS score;
+-- +goose StatementEnd
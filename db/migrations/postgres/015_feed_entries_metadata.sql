@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Item metadata as published (score, comments, summary, ...), so posts can
+-- be re-rendered later with the same fields
+ALTER TABLE feed_entries ADD COLUMN IF NOT EXISTS metadata JSONB;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE feed_entries DROP COLUMN IF EXISTS metadata;
+-- +goose StatementEnd
@@ -0,0 +1,138 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// maxLineSize bounds a single record; embeddings make lines long.
+const maxLineSize = 64 << 20
+
+// Files resolves pattern, a file, a directory or a glob, to the archive files
+// it names, in lexical order, which is also write order for the Writer's
+// file names.
+func Files(pattern string) ([]string, error) {
+	if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+		pattern = filepath.Join(pattern, "*.jsonl*")
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("archive: invalid path %q: %w", pattern, err)
+	}
+
+	files := matches[:0]
+	for _, m := range matches {
+		if strings.HasSuffix(m, ".jsonl") || strings.HasSuffix(m, ".jsonl.gz") ||
+			strings.HasSuffix(m, ".ndjson") || strings.HasSuffix(m, ".ndjson.gz") {
+			files = append(files, m)
+		}
+	}
+	slices.SortFunc(files, compareFiles)
+	return files, nil
+}
+
+// compareFiles orders rotated files numerically, so name-2026-01-02.10.jsonl
+// comes after name-2026-01-02.9.jsonl.
+func compareFiles(a, b string) int {
+	stemA, serialA := splitSerial(a)
+	stemB, serialB := splitSerial(b)
+	if c := strings.Compare(stemA, stemB); c != 0 {
+		return c
+	}
+	return serialA - serialB
+}
+
+func splitSerial(path string) (string, int) {
+	name := strings.TrimSuffix(path, ".gz")
+	name = strings.TrimSuffix(strings.TrimSuffix(name, ".jsonl"), ".ndjson")
+
+	dot := strings.LastIndexByte(name, '.')
+	if dot < 0 {
+		return name, 0
+	}
+	serial := 0
+	for _, c := range name[dot+1:] {
+		if c < '0' || c > '9' {
+			return name, 0
+		}
+		serial = serial*10 + int(c-'0')
+	}
+	return name[:dot], serial
+}
+
+// Reader decodes records from one archive file.
+type Reader struct {
+	file    *os.File
+	gz      *gzip.Reader
+	scanner *bufio.Scanner
+	line    int
+}
+
+func Open(path string) (*Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("archive: open %s: %w", path, err)
+	}
+
+	r := &Reader{file: file}
+	var in io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("archive: open %s: %w", path, err)
+		}
+		r.gz = gz
+		in = gz
+	}
+
+	r.scanner = bufio.NewScanner(in)
+	r.scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	return r, nil
+}
+
+// Next returns the next record, or io.EOF after the last one. Blank lines
+// are skipped. A file that was still being written ends early rather than
+// failing.
+func (r *Reader) Next() (*Record, error) {
+	for r.scanner.Scan() {
+		r.line++
+		line := r.scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var rec Record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return nil, fmt.Errorf("archive: %s line %d: %w", r.file.Name(), r.line, err)
+		}
+		return &rec, nil
+	}
+
+	if err := r.scanner.Err(); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("archive: read %s: %w", r.file.Name(), err)
+	}
+	return nil, io.EOF
+}
+
+// Line is the number of lines read so far.
+func (r *Reader) Line() int {
+	return r.line
+}
+
+func (r *Reader) Close() error {
+	if r.gz != nil {
+		_ = r.gz.Close()
+	}
+	return r.file.Close()
+}
@@ -0,0 +1,84 @@
+// Package archive reads and writes JSON Lines archives of items, one item
+// per line, optionally gzip-compressed.
+package archive
+
+import (
+	"cartero/internal/types"
+	"net/url"
+	"time"
+)
+
+// Record is one archived item. It keeps everything the pipeline attached to
+// the item, so a replay sees the item as it was published.
+type Record struct {
+	ID              string         `json:"id"`
+	Title           string         `json:"title"`
+	URL             string         `json:"url"`
+	Source          string         `json:"source"`
+	Route           string         `json:"route"`
+	Timestamp       time.Time      `json:"timestamp"`
+	ArchivedAt      time.Time      `json:"archived_at"`
+	Score           float64        `json:"score"`
+	MatchedKeywords string         `json:"matched_keywords,omitempty"`
+	Article         *Article       `json:"article,omitempty"`
+	Metadata        map[string]any `json:"metadata,omitempty"`
+	Content         any            `json:"content,omitempty"`
+	Embedding       [][]float32    `json:"embedding,omitempty"`
+}
+
+type Article struct {
+	Text        string `json:"text,omitempty"`
+	Image       string `json:"image,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// NewRecord captures item. The embedding chunks are only kept when asked
+// for, since they dwarf the rest of the record.
+func NewRecord(item *types.Item, archivedAt time.Time, withEmbedding bool) *Record {
+	r := &Record{
+		ID:              item.GetID(),
+		Title:           item.GetTitle(),
+		URL:             item.GetLink().String(),
+		Source:          item.GetSource(),
+		Route:           item.GetRoute(),
+		Timestamp:       item.GetTimestamp(),
+		ArchivedAt:      archivedAt.UTC(),
+		Score:           item.GetScore(),
+		MatchedKeywords: item.GetMatchedKeywords(),
+		Metadata:        item.GetMetadata(),
+		Content:         item.GetContent(),
+	}
+	if a := item.GetArticle(); a != nil {
+		r.Article = &Article{Text: a.Text, Image: a.Image, Description: a.Description}
+	}
+	if withEmbedding {
+		r.Embedding = item.GetEmbedding()
+	}
+	return r
+}
+
+// Item rebuilds the archived item for route. Numbers in Metadata and
+// Content come back as float64, as with any JSON round trip.
+func (r *Record) Item(route string) *types.Item {
+	link, _ := url.Parse(r.URL)
+
+	item := &types.Item{
+		ID:              r.ID,
+		Title:           r.Title,
+		URL:             link,
+		Content:         r.Content,
+		Metadata:        r.Metadata,
+		Source:          r.Source,
+		Route:           route,
+		MatchedKeywords: r.MatchedKeywords,
+		Timestamp:       r.Timestamp,
+		Embedding:       r.Embedding,
+	}
+	if item.Metadata == nil {
+		item.Metadata = make(map[string]any)
+	}
+	if r.Article != nil {
+		item.TextContent = &types.Article{Text: r.Article.Text, Image: r.Article.Image, Description: r.Article.Description}
+	}
+	return item
+}
@@ -0,0 +1,171 @@
+package archive
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// WriterOptions controls file naming and rotation. Files are named
+// <prefix>-<date>.jsonl, then <prefix>-<date>.1.jsonl and so on once a file
+// reaches MaxSize bytes.
+type WriterOptions struct {
+	Directory string
+	Prefix    string
+	// MaxSize rotates to a new file once the current one reaches this many
+	// bytes on disk. Zero disables size rotation.
+	MaxSize int64
+	// Daily starts a new file on each UTC day; otherwise the date in the
+	// name is the day the file was opened.
+	Daily bool
+	Gzip  bool
+}
+
+// Writer appends records to the current archive file, rotating by size and
+// day. Each record is flushed to disk before Write returns.
+type Writer struct {
+	opts WriterOptions
+
+	mu     sync.Mutex
+	file   *os.File
+	gz     *gzip.Writer
+	size   int64
+	day    string
+	serial int
+}
+
+func NewWriter(opts WriterOptions) *Writer {
+	return &Writer{opts: opts}
+}
+
+func (w *Writer) Write(r *Record, now time.Time) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("archive: encode record: %w", err)
+	}
+	line = append(line, '\n')
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.rotate(now.UTC()); err != nil {
+		return err
+	}
+
+	var out io.Writer = w.file
+	if w.gz != nil {
+		out = w.gz
+	}
+	if _, err := out.Write(line); err != nil {
+		return fmt.Errorf("archive: write %s: %w", w.file.Name(), err)
+	}
+	if w.gz != nil {
+		if err := w.gz.Flush(); err != nil {
+			return fmt.Errorf("archive: write %s: %w", w.file.Name(), err)
+		}
+	}
+
+	if info, err := w.file.Stat(); err == nil {
+		w.size = info.Size()
+	}
+	return nil
+}
+
+// rotate makes sure a file is open and still within the rotation limits.
+func (w *Writer) rotate(now time.Time) error {
+	day := now.Format("2006-01-02")
+
+	switch {
+	case w.file == nil:
+		w.day, w.serial = day, 0
+	case w.opts.Daily && day != w.day:
+		if err := w.closeFile(); err != nil {
+			return err
+		}
+		w.day, w.serial = day, 0
+	case w.opts.MaxSize > 0 && w.size >= w.opts.MaxSize:
+		if err := w.closeFile(); err != nil {
+			return err
+		}
+		w.serial++
+	default:
+		return nil
+	}
+
+	return w.open()
+}
+
+// open opens the first file of the current day, from the current serial on,
+// that still has room, so a restart keeps appending where it left off.
+func (w *Writer) open() error {
+	if err := os.MkdirAll(w.opts.Directory, 0o755); err != nil {
+		return fmt.Errorf("archive: create directory: %w", err)
+	}
+
+	for {
+		path := filepath.Join(w.opts.Directory, w.filename())
+		info, err := os.Stat(path)
+		if err == nil && w.opts.MaxSize > 0 && info.Size() >= w.opts.MaxSize {
+			w.serial++
+			continue
+		}
+
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("archive: open %s: %w", path, err)
+		}
+		w.file = file
+		w.size = 0
+		if info, err := file.Stat(); err == nil {
+			w.size = info.Size()
+		}
+		// Appending to an existing file starts a new gzip member, which
+		// readers treat as one continuous stream.
+		if w.opts.Gzip {
+			w.gz = gzip.NewWriter(file)
+		}
+		return nil
+	}
+}
+
+func (w *Writer) filename() string {
+	name := w.opts.Prefix + "-" + w.day
+	if w.serial > 0 {
+		name += "." + strconv.Itoa(w.serial)
+	}
+	name += ".jsonl"
+	if w.opts.Gzip {
+		name += ".gz"
+	}
+	return name
+}
+
+func (w *Writer) closeFile() error {
+	if w.file == nil {
+		return nil
+	}
+	defer func() { w.file, w.gz = nil, nil }()
+
+	if w.gz != nil {
+		if err := w.gz.Close(); err != nil {
+			_ = w.file.Close()
+			return fmt.Errorf("archive: close %s: %w", w.file.Name(), err)
+		}
+	}
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("archive: close %s: %w", w.file.Name(), err)
+	}
+	return nil
+}
+
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.closeFile()
+}
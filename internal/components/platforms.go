@@ -12,6 +12,8 @@ type PlatformComponent struct {
 	discordPlatform   *platforms.DiscordPlatform
 	blueskyPlatform   *platforms.BlueskyPlatform
 	telegramPlatform  *platforms.TelegramPlatform
+	mastodonPlatform  *platforms.MastodonPlatform
+	matrixPlatform    *platforms.MatrixPlatform
 	ollamaPlatforms   map[string]*platforms.OllamaPlatform
 	embeddingPlatform platforms.Embedder
 	rerankerPlatform  platforms.Reranker
@@ -79,6 +81,34 @@ func (c *PlatformComponent) Initialize(ctx context.Context) error {
 		c.telegramPlatform = telegram
 	}
 
+	if mastodonCfg, exists := c.config["mastodon"]; exists && mastodonCfg.Enabled {
+		mastodon, err := platforms.NewMastodonPlatform(&mastodonCfg.Settings.MastodonPlatformSettings)
+		if err != nil {
+			return fmt.Errorf("failed to create mastodon platform: %w", err)
+		}
+		if err := mastodon.Validate(); err != nil {
+			return fmt.Errorf("mastodon platform validation failed: %w", err)
+		}
+		if err := mastodon.Initialize(ctx); err != nil {
+			return fmt.Errorf("mastodon platform initialization failed: %w", err)
+		}
+		c.mastodonPlatform = mastodon
+	}
+
+	if matrixCfg, exists := c.config["matrix"]; exists && matrixCfg.Enabled {
+		matrix, err := platforms.NewMatrixPlatform(&matrixCfg.Settings.MatrixPlatformSettings)
+		if err != nil {
+			return fmt.Errorf("failed to create matrix platform: %w", err)
+		}
+		if err := matrix.Validate(); err != nil {
+			return fmt.Errorf("matrix platform validation failed: %w", err)
+		}
+		if err := matrix.Initialize(ctx); err != nil {
+			return fmt.Errorf("matrix platform initialization failed: %w", err)
+		}
+		c.matrixPlatform = matrix
+	}
+
 	for _, cfg := range c.config {
 		if !cfg.Enabled {
 			continue
@@ -125,6 +155,12 @@ func (c *PlatformComponent) Close(ctx context.Context) error {
 	if c.telegramPlatform != nil {
 		_ = c.telegramPlatform.Close(ctx)
 	}
+	if c.mastodonPlatform != nil {
+		_ = c.mastodonPlatform.Close(ctx)
+	}
+	if c.matrixPlatform != nil {
+		_ = c.matrixPlatform.Close(ctx)
+	}
 	return nil
 }
 
@@ -140,6 +176,14 @@ func (c *PlatformComponent) Telegram() *platforms.TelegramPlatform {
 	return c.telegramPlatform
 }
 
+func (c *PlatformComponent) Mastodon() *platforms.MastodonPlatform {
+	return c.mastodonPlatform
+}
+
+func (c *PlatformComponent) Matrix() *platforms.MatrixPlatform {
+	return c.matrixPlatform
+}
+
 func (c *PlatformComponent) Embedder() platforms.Embedder {
 	return c.embeddingPlatform
 }
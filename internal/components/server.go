@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 
+	"cartero/internal/config"
 	"cartero/internal/platforms"
 	"cartero/internal/server/feed"
+	"cartero/internal/server/feed/handler"
 	"cartero/internal/storage"
 )
 
@@ -18,6 +20,7 @@ type ServerConfig struct {
 	SiteName          string
 	SiteDescription   string
 	SearchMaxDistance float64
+	FeedGenerator     config.FeedGeneratorSettings
 }
 
 type ServerComponent struct {
@@ -58,6 +61,10 @@ func (c *ServerComponent) Initialize(ctx context.Context) error {
 	embedder := platformComp.Embedder()
 
 	for _, cfg := range c.configs {
+		// Feed URIs are under the account that publishes the records.
+		if cfg.FeedGenerator.PublisherDID == "" && platformComp.Bluesky() != nil {
+			cfg.FeedGenerator.PublisherDID = platformComp.Bluesky().DID()
+		}
 		if err := c.startServer(ctx, cfg, entryStore, embedder); err != nil {
 			return err
 		}
@@ -88,6 +95,9 @@ func (c *ServerComponent) startServer(ctx context.Context, cfg ServerConfig, ent
 		SiteName:          cfg.SiteName,
 		SiteDescription:   cfg.SiteDescription,
 		SearchMaxDistance: cfg.SearchMaxDistance,
+		ServiceDID:        cfg.FeedGenerator.ServiceDID,
+		PublisherDID:      cfg.FeedGenerator.PublisherDID,
+		BlueskyFeeds:      blueskyFeeds(cfg.FeedGenerator.Feeds),
 	}, entryStore, embedder)
 
 	if err := server.Start(ctx); err != nil {
@@ -98,6 +108,20 @@ func (c *ServerComponent) startServer(ctx context.Context, cfg ServerConfig, ent
 	return nil
 }
 
+func blueskyFeeds(settings []config.BlueskyFeedSettings) []handler.BlueskyFeed {
+	feeds := make([]handler.BlueskyFeed, 0, len(settings))
+	for _, f := range settings {
+		feeds = append(feeds, handler.BlueskyFeed{
+			Name:      f.Name,
+			Target:    f.Target,
+			Interests: f.Interests,
+			ByScore:   f.Sort == "score",
+			Window:    config.ParseDuration(f.Window, 0),
+		})
+	}
+	return feeds
+}
+
 func (c *ServerComponent) Close(ctx context.Context) error {
 	for _, server := range c.servers {
 		if err := server.Shutdown(ctx); err != nil {
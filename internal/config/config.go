@@ -1,10 +1,13 @@
 package config
 
 import (
+	"cartero/internal/dag"
+	"cartero/internal/processors/names"
 	"cartero/internal/utils/file"
 	"cartero/internal/utils/keywords"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -22,6 +25,8 @@ type Config struct {
 	Targets    map[string]TargetConfig    `toml:"targets"`
 	Interests  InterestConfig             `toml:"interests"`
 	Blocklist  BlocklistConfig            `toml:"blocklist"`
+	Outbox     OutboxConfig               `toml:"outbox"`
+	Updates    UpdatesConfig              `toml:"updates"`
 }
 
 type InterestConfig struct {
@@ -48,6 +53,22 @@ type BotConfig struct {
 	Sleep    string `toml:"sleep"`
 }
 
+type OutboxConfig struct {
+	Interval    string `toml:"interval"`
+	Backoff     string `toml:"backoff"`
+	MaxBackoff  string `toml:"max_backoff"`
+	MaxAttempts int    `toml:"max_attempts"`
+	BatchSize   int    `toml:"batch_size"`
+}
+
+type UpdatesConfig struct {
+	Enabled        bool   `toml:"enabled"`
+	Interval       string `toml:"interval"`
+	Window         string `toml:"window"`
+	Limit          int    `toml:"limit"`
+	DeleteOnChange bool   `toml:"delete_on_change"`
+}
+
 type StorageConfig struct {
 	Type string `toml:"type"`
 	DSN  string `toml:"dsn"`
@@ -61,12 +82,29 @@ type PlatformConfig struct {
 }
 
 type PlatformSettings struct {
+	// AccessToken is decoded here rather than in the Mastodon and Matrix
+	// settings, which would both claim the access_token key; Load copies
+	// it into them.
+	AccessToken string `toml:"access_token"`
+
 	DiscordPlatformSettings
 	OllamaPlatformSettings
 	BlueskyPlatformSettings
 	TelegramPlatformSettings
 	OpenAIPlatformSettings
 	RerankerPlatformSettings
+	MastodonPlatformSettings
+	MatrixPlatformSettings
+}
+
+type MatrixPlatformSettings struct {
+	HomeserverURL string `toml:"homeserver_url"`
+	AccessToken   string `toml:"-"`
+}
+
+type MastodonPlatformSettings struct {
+	InstanceURL string `toml:"instance_url"`
+	AccessToken string `toml:"-"`
 }
 
 type RerankerPlatformSettings struct {
@@ -109,6 +147,10 @@ type SourceSettings struct {
 	RSSSettings
 	LobstersSettings
 	ScraperSettings
+	JSONLSettings
+	RedditSettings
+	GitHubSettings
+	ArxivSettings
 }
 
 type HackerNewsSettings struct {
@@ -132,6 +174,49 @@ type LobstersSettings struct {
 	ExcludeCategories []string `toml:"exclude_categories"`
 }
 
+// RedditSettings picks the subreddits and listing (hot, new, top, rising)
+// to read; time_window (hour, day, week, month, year, all) applies to top.
+// Self-posts are skipped unless include_self is set. With client_id and
+// client_secret the OAuth API is used instead of the public listings.
+type RedditSettings struct {
+	Subreddits    []string `toml:"subreddits"`
+	Listing       string   `toml:"listing"`
+	Period        string   `toml:"time_window"`
+	MinScore      int      `toml:"min_score"`
+	IncludeFlairs []string `toml:"include_flairs"`
+	ExcludeFlairs []string `toml:"exclude_flairs"`
+	IncludeSelf   bool     `toml:"include_self"`
+	ClientID      string   `toml:"client_id"`
+	ClientSecret  string   `toml:"client_secret"`
+}
+
+// GitHubSettings configures the github source. Mode is "releases" of
+// repos, "search" by topic and/or language, or "starred" by users; window
+// bounds how old a release, new repo or star may be (default 168h).
+type GitHubSettings struct {
+	Mode     string   `toml:"mode"`
+	Repos    []string `toml:"repos"`
+	Topic    string   `toml:"topic"`
+	Language string   `toml:"language"`
+	Users    []string `toml:"users"`
+	Window   string   `toml:"window"`
+	Token    string   `toml:"token"`
+}
+
+// ArxivSettings selects papers in any of categories (cs.LG, cs.PL, ...)
+// that also match query, in arXiv search syntax. max_items is the number
+// of newest results asked for.
+type ArxivSettings struct {
+	Categories []string `toml:"categories"`
+	Query      string   `toml:"query"`
+}
+
+// JSONLSettings points the jsonl source at archives written by the jsonl
+// target: a file, a directory or a glob.
+type JSONLSettings struct {
+	Path string `toml:"path"`
+}
+
 type ScraperSettings struct {
 	ScraperType string         `toml:"scraper_type"`
 	ScraperName string         `toml:"scraper_name"`
@@ -140,13 +225,16 @@ type ScraperSettings struct {
 }
 
 type ProcessorConfig struct {
-	Type      string            `toml:"type"`
-	Enabled   bool              `toml:"enabled"`
-	DependsOn []string          `toml:"depends_on"`
-	Settings  ProcessorSettings `toml:"settings"`
+	Type              string            `toml:"type"`
+	Enabled           bool              `toml:"enabled"`
+	DependsOn         []string          `toml:"depends_on"`
+	OverrideDependsOn bool              `toml:"override_depends_on"`
+	Order             int               `toml:"order"`
+	Settings          ProcessorSettings `toml:"settings"`
 }
 
 type ProcessorSettings struct {
+	LimitSettings
 	DedupeSettings
 	ScoreFilterSettings
 	PublishedAtFilterSettings
@@ -155,6 +243,16 @@ type ProcessorSettings struct {
 	TemplateSettings
 	ExtractTextSettings
 	EmbedTextSettings
+	RerankSettings
+	RateLimitSettings
+}
+
+type LimitSettings struct {
+	Limit int `toml:"limit"`
+}
+
+type RateLimitSettings struct {
+	Window string `toml:"window"`
 }
 
 type DedupeSettings struct {
@@ -173,6 +271,12 @@ type EmbedTextSettings struct {
 	CacheTTL    string `toml:"cache_ttl"`
 }
 
+type RerankSettings struct {
+	TopK   int     `toml:"top_k"`
+	Query  string  `toml:"query"`
+	Weight float64 `toml:"weight"`
+}
+
 type PublishedAtFilterSettings struct {
 	After  string `toml:"after"`
 	Before string `toml:"before"`
@@ -187,7 +291,6 @@ type ExtractFieldsSettings struct {
 }
 
 type ExtractTextSettings struct {
-	Limit            int    `toml:"limit"`
 	MinContentLength int    `toml:"min_content_length"`
 	Concurrency      int    `toml:"concurrency"`
 	TimeoutSeconds   int    `toml:"timeout_seconds"`
@@ -200,21 +303,57 @@ type TemplateSettings struct {
 }
 
 type TargetConfig struct {
-	Type     string         `toml:"type"`
-	Enabled  bool           `toml:"enabled"`
-	Settings TargetSettings `toml:"settings"`
+	Type       string                  `toml:"type"`
+	Enabled    bool                    `toml:"enabled"`
+	Settings   TargetSettings          `toml:"settings"`
+	Processors TargetProcessorSettings `toml:"processors"`
+}
+
+type TargetProcessorSettings struct {
+	Filters      []string `toml:"filters"`
+	MinScore     float64  `toml:"min_score"`
+	Limit        int      `toml:"limit"`
+	SummaryModel string   `toml:"summary_model"`
 }
 
 type TargetSettings struct {
+	CommonTargetSettings
 	DiscordTargetSettings
 	FeedTargetSettings
 	BlueskyTargetSettings
 	TelegramTargetSettings
-}
-
+	WebhookTargetSettings
+	MastodonTargetSettings
+	EmailTargetSettings
+	SlackTargetSettings
+	MatrixTargetSettings
+	PushTargetSettings
+	MarkdownTargetSettings
+	StaticSiteTargetSettings
+	ReadLaterTargetSettings
+	JSONLTargetSettings
+}
+
+// CommonTargetSettings holds keys shared by several target types, so they
+// aren't declared twice in the embedded settings structs.
+type CommonTargetSettings struct {
+	Template    string   `toml:"template"`
+	URL         string   `toml:"url"`
+	Timeout     string   `toml:"timeout"`
+	ChannelID   string   `toml:"channel_id"`
+	ChannelType string   `toml:"channel_type"`
+	Tags        []string `toml:"tags"`
+	Token       string   `toml:"token"`
+	Directory   string   `toml:"directory"`
+}
+
+// DiscordTargetSettings controls how forum threads are tagged. forum_tags
+// maps a matched interest or source to a tag name or ID; an empty value
+// means no tag. Names without a mapping are matched against the forum's tags
+// by name, and create_missing_tags adds a tag when none matches.
 type DiscordTargetSettings struct {
-	ChannelID   string `toml:"channel_id"`
-	ChannelType string `toml:"channel_type"`
+	ForumTags         map[string]string `toml:"forum_tags"`
+	CreateMissingTags bool              `toml:"create_missing_tags"`
 }
 
 type FeedTargetSettings struct {
@@ -225,14 +364,137 @@ type FeedTargetSettings struct {
 	SiteName          string  `toml:"site_name"`
 	SiteDescription   string  `toml:"site_description"`
 	SearchMaxDistance float64 `toml:"search_max_distance"`
-}
-
+	// FeedGenerator serves the posts of bluesky targets as custom feeds.
+	FeedGenerator FeedGeneratorSettings `toml:"feed_generator"`
+}
+
+// FeedGeneratorSettings makes the feed server a Bluesky feed generator.
+// ServiceDID identifies the server, did:web of the site_url host when
+// empty; PublisherDID is the account the feed records live in, the bluesky
+// platform's account when empty.
+type FeedGeneratorSettings struct {
+	ServiceDID   string                `toml:"service_did"`
+	PublisherDID string                `toml:"publisher_did"`
+	Feeds        []BlueskyFeedSettings `toml:"feeds"`
+}
+
+// BlueskyFeedSettings is one custom feed, published under the record key
+// Name. It serves the posts the bluesky target Target made for entries that
+// matched one of Interests, or all of them, ordered by "recent" or "score".
+// Window limits how far back the feed reaches.
+type BlueskyFeedSettings struct {
+	Name        string   `toml:"name"`
+	DisplayName string   `toml:"display_name"`
+	Description string   `toml:"description"`
+	Target      string   `toml:"target"`
+	Interests   []string `toml:"interests"`
+	Sort        string   `toml:"sort"`
+	Window      string   `toml:"window"`
+}
+
+// BlueskyTargetSettings sets the post languages. With thread, the summary
+// is posted as up to thread_max_posts replies under the post.
 type BlueskyTargetSettings struct {
-	Languages []string `toml:"languages"`
+	Languages      []string `toml:"languages"`
+	Thread         bool     `toml:"thread"`
+	ThreadMaxPosts int      `toml:"thread_max_posts"`
 }
 
+// TelegramTargetSettings picks the chat and, for forum supergroups, the
+// topic to post in.
 type TelegramTargetSettings struct {
-	ChatID int64 `toml:"chat_id"`
+	ChatID   int64 `toml:"chat_id"`
+	ThreadID int   `toml:"message_thread_id"`
+}
+
+type MastodonTargetSettings struct {
+	Visibility  string `toml:"visibility"`
+	Language    string `toml:"language"`
+	SpoilerText string `toml:"spoiler_text"`
+	MaxChars    int    `toml:"max_chars"`
+}
+
+type WebhookTargetSettings struct {
+	Method          string            `toml:"method"`
+	Headers         map[string]string `toml:"headers"`
+	Secret          string            `toml:"secret"`
+	SignatureHeader string            `toml:"signature_header"`
+}
+
+// PushTargetSettings configures the ntfy and gotify targets. The shared url
+// key is the ntfy topic URL or the Gotify server URL; token is an ntfy access
+// token or a Gotify app token.
+type PushTargetSettings struct {
+	Priorities []PriorityBand `toml:"priorities"`
+}
+
+// PriorityBand maps items scoring at least MinScore to a notification
+// priority.
+type PriorityBand struct {
+	MinScore float64 `toml:"min_score"`
+	Priority int     `toml:"priority"`
+}
+
+// MarkdownTargetSettings configures the notes vault. filename and
+// index_filename are templates for paths relative to directory, without the
+// .md extension; the shared template key renders the note body.
+type MarkdownTargetSettings struct {
+	Filename      string `toml:"filename"`
+	IndexFilename string `toml:"index_filename"`
+}
+
+// ReadLaterTargetSettings holds the Wallabag OAuth client and account used
+// for the password grant. Linkding and Readeck use the shared token key.
+type ReadLaterTargetSettings struct {
+	ClientID     string `toml:"client_id"`
+	ClientSecret string `toml:"client_secret"`
+	Username     string `toml:"username"`
+	Password     string `toml:"password"`
+}
+
+// JSONLTargetSettings configures the item archive written to directory.
+// Files rotate each UTC day when rotate_daily is set and whenever one reaches
+// max_size_mb.
+type JSONLTargetSettings struct {
+	MaxSizeMB        int  `toml:"max_size_mb"`
+	RotateDaily      bool `toml:"rotate_daily"`
+	Gzip             bool `toml:"gzip"`
+	IncludeEmbedding bool `toml:"include_embedding"`
+}
+
+// StaticSiteTargetSettings configures the static site export. The site
+// metadata, feed_size and max_items keys are shared with the feed target.
+type StaticSiteTargetSettings struct {
+	OutputDir string `toml:"output_dir"`
+	PerPage   int    `toml:"per_page"`
+}
+
+type MatrixTargetSettings struct {
+	RoomID string `toml:"room_id"`
+}
+
+// SlackTargetSettings configures the Slack target. With only the shared url
+// key set it posts to an incoming webhook; with bot_token it uses
+// chat.postMessage on channel_id, which thread replies need.
+type SlackTargetSettings struct {
+	BotToken string `toml:"bot_token"`
+}
+
+// EmailTargetSettings configures the SMTP digest. The shared template key
+// selects the plain-text template.
+type EmailTargetSettings struct {
+	SMTPHost     string   `toml:"smtp_host"`
+	SMTPPort     int      `toml:"smtp_port"`
+	SMTPUsername string   `toml:"smtp_username"`
+	SMTPPassword string   `toml:"smtp_password"`
+	SMTPInsecure bool     `toml:"smtp_insecure"`
+	From         string   `toml:"from"`
+	To           []string `toml:"to"`
+	Subject      string   `toml:"subject"`
+	SendAt       string   `toml:"send_at"`
+	TimeZone     string   `toml:"time_zone"`
+	GroupBy      string   `toml:"group_by"`
+	HTMLTemplate string   `toml:"html_template"`
 }
 
 func Load(path string) (*Config, error) {
@@ -338,6 +600,131 @@ func validateConfig(config *Config) error {
 		config.Redis.Addr = "localhost:6379"
 	}
 
+	if config.Outbox.MaxAttempts == 0 {
+		config.Outbox.MaxAttempts = 10
+	}
+
+	if config.Outbox.BatchSize == 0 {
+		config.Outbox.BatchSize = 50
+	}
+
+	if config.Updates.Limit == 0 {
+		config.Updates.Limit = 200
+	}
+
+	for name, platform := range config.Platforms {
+		platform.Settings.MastodonPlatformSettings.AccessToken = platform.Settings.AccessToken
+		platform.Settings.MatrixPlatformSettings.AccessToken = platform.Settings.AccessToken
+		config.Platforms[name] = platform
+	}
+
+	for _, d := range []string{config.Outbox.Interval, config.Outbox.Backoff, config.Outbox.MaxBackoff, config.Updates.Interval, config.Updates.Window} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(d); err != nil {
+			return fmt.Errorf("invalid duration %q: %w", d, err)
+		}
+	}
+
+	if err := validateDependsOn(config.Processors); err != nil {
+		return err
+	}
+
+	for name, target := range config.Targets {
+		for _, proc := range target.Processors.Filters {
+			procCfg, exists := config.Processors[proc]
+			if !exists {
+				return fmt.Errorf("target %s: processor %s not found", name, proc)
+			}
+			// Enabled processors run in the shared chain for every route;
+			// running one again per target would apply it twice.
+			if procCfg.Enabled {
+				return fmt.Errorf("target %s: processor %s is enabled in the shared chain, set enabled = false to run it for this target only", name, proc)
+			}
+		}
+
+		if gen := &target.Settings.FeedGenerator; len(gen.Feeds) > 0 && gen.ServiceDID == "" {
+			u, err := url.Parse(target.Settings.SiteURL)
+			if err != nil || u.Hostname() == "" {
+				return fmt.Errorf("target %s: feed_generator needs a service_did or a site_url", name)
+			}
+			gen.ServiceDID = "did:web:" + u.Hostname()
+			config.Targets[name] = target
+		}
+
+		for _, feed := range target.Settings.FeedGenerator.Feeds {
+			if feed.Name == "" || feed.Target == "" {
+				return fmt.Errorf("target %s: feed_generator feeds need a name and a target", name)
+			}
+			if feed.Sort != "" && feed.Sort != "recent" && feed.Sort != "score" {
+				return fmt.Errorf("target %s: feed %s: sort must be recent or score", name, feed.Name)
+			}
+			if feed.Window != "" {
+				if _, err := time.ParseDuration(feed.Window); err != nil {
+					return fmt.Errorf("target %s: feed %s: invalid window %q: %w", name, feed.Name, feed.Window, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// builtinProcessors are part of every chain without being configured, so
+// depends_on may name them too.
+var builtinProcessors = []string{names.Blocklist, names.PublishedDedupe, names.ExtractText, names.Rank, names.Diversify, names.MinScore}
+
+type processorNode struct {
+	name string
+	deps []string
+}
+
+func (n processorNode) GetName() string           { return n.name }
+func (n processorNode) GetDependencies() []string { return n.deps }
+
+// validateDependsOn checks that depends_on only names known processors and
+// that the declared dependencies don't form a cycle. Names resolve the way
+// the chains are built: a config key stands for its processor's type, and
+// processors other than rate limits are named by type.
+func validateDependsOn(processors map[string]ProcessorConfig) error {
+	known := make(map[string]bool)
+	for _, name := range builtinProcessors {
+		known[name] = true
+	}
+	for name, proc := range processors {
+		known[name] = true
+		known[proc.Type] = true
+	}
+
+	resolve := func(dep string) string {
+		if proc, ok := processors[dep]; ok {
+			return proc.Type
+		}
+		return dep
+	}
+
+	sorter := dag.NewTopologicalSorter()
+	for name, proc := range processors {
+		node := processorNode{name: proc.Type}
+		if proc.Type == names.RateLimit {
+			node.name = name
+		}
+		for _, dep := range proc.DependsOn {
+			if !known[dep] {
+				return fmt.Errorf("processor %s: depends_on %s is not a processor", name, dep)
+			}
+			if dep == name || resolve(dep) == node.name {
+				return fmt.Errorf("processor %s: depends_on references itself", name)
+			}
+			node.deps = append(node.deps, resolve(dep))
+		}
+		sorter.AddNode(node.name, node)
+	}
+	if _, err := sorter.Sort(); err != nil {
+		return fmt.Errorf("processors depends_on: %w", err)
+	}
+
 	return nil
 }
 
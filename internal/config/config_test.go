@@ -0,0 +1,97 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateDependsOn(t *testing.T) {
+	tests := []struct {
+		name       string
+		processors map[string]ProcessorConfig
+		wantErr    string
+	}{
+		{
+			name: "config key, type and built-in",
+			processors: map[string]ProcessorConfig{
+				"score_filter": {Type: "filter_score", DependsOn: []string{"dedupe", "rank"}},
+				"dedupe":       {Type: "dedupe"},
+				"ai":           {Type: "summary", DependsOn: []string{"score_filter", "extract_text"}},
+			},
+		},
+		{
+			name: "unknown name",
+			processors: map[string]ProcessorConfig{
+				"ai": {Type: "summary", DependsOn: []string{"extract"}},
+			},
+			wantErr: "depends_on extract is not a processor",
+		},
+		{
+			name: "self reference by type",
+			processors: map[string]ProcessorConfig{
+				"ai": {Type: "summary", DependsOn: []string{"summary"}},
+			},
+			wantErr: "references itself",
+		},
+		{
+			name: "cycle through config keys",
+			processors: map[string]ProcessorConfig{
+				"score_filter": {Type: "filter_score", DependsOn: []string{"ai"}},
+				"ai":           {Type: "summary", DependsOn: []string{"fields"}},
+				"fields":       {Type: "extract_fields", DependsOn: []string{"score_filter"}},
+			},
+			wantErr: "circular dependency detected between: extract_fields, filter_score, summary",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDependsOn(tt.processors)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validateDependsOn: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadPlatformAccessTokens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	data := `
+[storage]
+dsn = "postgres://localhost/cartero"
+
+[platforms.mastodon]
+type = "mastodon"
+[platforms.mastodon.settings]
+instance_url = "https://mastodon.social"
+access_token = "mastodon-token"
+
+[platforms.matrix]
+type = "matrix"
+[platforms.matrix.settings]
+homeserver_url = "https://matrix.example.org"
+access_token = "matrix-token"
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := cfg.Platforms["mastodon"].Settings.MastodonPlatformSettings.AccessToken; got != "mastodon-token" {
+		t.Errorf("mastodon access token = %q", got)
+	}
+	if got := cfg.Platforms["matrix"].Settings.MatrixPlatformSettings.AccessToken; got != "matrix-token" {
+		t.Errorf("matrix access token = %q", got)
+	}
+}
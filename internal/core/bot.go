@@ -14,7 +14,9 @@ type Bot struct {
 	name       string
 	pipeline   *Pipeline
 	filters    *filters.Chain
-	targets    Targets
+	chains     map[string]*filters.Chain
+	outbox     *Outbox
+	updates    *Updates
 	interval   time.Duration
 	runOnce    bool
 	state      types.StateAccessor
@@ -29,7 +31,9 @@ type BotConfig struct {
 	Name       string
 	Pipeline   *Pipeline
 	Filters    *filters.Chain
-	Targets    Targets
+	Chains     map[string]*filters.Chain
+	Outbox     *Outbox
+	Updates    *Updates
 	Interval   time.Duration
 	RunOnce    bool
 	State      types.StateAccessor
@@ -45,7 +49,9 @@ func NewBot(config BotConfig) *Bot {
 		name:       config.Name,
 		pipeline:   config.Pipeline,
 		filters:    config.Filters,
-		targets:    config.Targets,
+		chains:     config.Chains,
+		outbox:     config.Outbox,
+		updates:    config.Updates,
 		interval:   config.Interval,
 		runOnce:    config.RunOnce,
 		state:      config.State,
@@ -86,12 +92,30 @@ func (b *Bot) runCycle(ctx context.Context) error {
 		return fmt.Errorf("filter: %w", err)
 	}
 
-	return b.targets.Publish(ctx, b.state, items, logger)
+	return b.pipeline.Publish(ctx, b.state, items, b.chains, logger)
 }
 
+// runOnceMode runs one cycle and then delivers everything the outbox has
+// due and every batch whose send time passed, even if the cycle failed:
+// nothing else runs between runs to send them.
 func (b *Bot) runOnceMode(ctx context.Context) error {
 	defer b.markStopped()
-	return b.runCycle(ctx)
+	logger := b.state.GetLogger()
+
+	err := b.runCycle(ctx)
+	if b.outbox != nil {
+		if drainErr := b.outbox.DrainAll(ctx); drainErr != nil {
+			logger.Error("outbox: drain failed", "error", drainErr)
+		}
+	}
+	for _, target := range b.pipeline.AllTargets() {
+		if batcher, ok := target.(types.Batcher); ok {
+			if flushErr := batcher.FlushDue(ctx); flushErr != nil {
+				logger.Error("failed to send batch", "target", target.Name(), "error", flushErr)
+			}
+		}
+	}
+	return err
 }
 
 func (b *Bot) runContinuousMode(ctx context.Context) error {
@@ -100,6 +124,13 @@ func (b *Bot) runContinuousMode(ctx context.Context) error {
 	ticker := time.NewTicker(b.interval)
 	defer ticker.Stop()
 
+	if b.outbox != nil {
+		go b.outbox.Run(ctx)
+	}
+	if b.updates != nil {
+		go b.updates.Run(ctx)
+	}
+
 	if err := b.executeRun(ctx); err != nil {
 		b.errorCh <- err
 	}
@@ -0,0 +1,212 @@
+package core
+
+import (
+	"cartero/internal/config"
+	"cartero/internal/processors/filters"
+	"cartero/internal/storage"
+	"cartero/internal/types"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Outbox retries deliveries that failed in the publish cycle. Failed
+// (item, target) pairs are persisted with their attempt count and the
+// worker drains them with exponential backoff until they either succeed or
+// exhaust max attempts, at which point they are dead-lettered. Items a rate
+// limit deferred wait here too; every delivery takes a slot from the
+// target's rate limits first.
+type Outbox struct {
+	pipeline    *Pipeline
+	chains      map[string]*filters.Chain
+	state       types.StateAccessor
+	interval    time.Duration
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	maxAttempts int
+	batchSize   int
+}
+
+func NewOutbox(pipeline *Pipeline, chains map[string]*filters.Chain, state types.StateAccessor, cfg config.OutboxConfig) *Outbox {
+	return &Outbox{
+		pipeline:    pipeline,
+		chains:      chains,
+		state:       state,
+		interval:    config.ParseDuration(cfg.Interval, time.Minute),
+		backoff:     config.ParseDuration(cfg.Backoff, time.Minute),
+		maxBackoff:  config.ParseDuration(cfg.MaxBackoff, 6*time.Hour),
+		maxAttempts: cfg.MaxAttempts,
+		batchSize:   cfg.BatchSize,
+	}
+}
+
+func (o *Outbox) Run(ctx context.Context) {
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := o.Drain(ctx); err != nil {
+				o.state.GetLogger().Error("outbox: drain failed", "error", err)
+			}
+		}
+	}
+}
+
+// Drain makes one attempt at up to a batch of deliveries that are due.
+func (o *Outbox) Drain(ctx context.Context) error {
+	_, err := o.drain(ctx)
+	return err
+}
+
+// DrainAll drains batches until no delivery is due. Run-once mode has no
+// worker to pick up what a single batch leaves behind.
+func (o *Outbox) DrainAll(ctx context.Context) error {
+	for {
+		n, err := o.drain(ctx)
+		if err != nil || n == 0 || n < o.batchSize {
+			return err
+		}
+	}
+}
+
+// drain returns how many deliveries it claimed. Claimed deliveries are
+// leased or rescheduled, so the next call doesn't see them again.
+func (o *Outbox) drain(ctx context.Context) (int, error) {
+	logger := o.state.GetLogger()
+	store := o.state.GetStorage()
+
+	targets := make(map[string]types.Target)
+	for _, target := range o.pipeline.AllTargets() {
+		targets[target.Name()] = target
+	}
+
+	entries, err := store.Outbox().Claim(ctx, o.batchSize, o.interval)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, entry := range entries {
+		target, ok := targets[entry.Target]
+		if !ok {
+			logger.Warn("outbox: target no longer configured", "item_id", entry.ItemID, "target", entry.Target)
+			o.fail(ctx, entry, fmt.Errorf("target %s is not configured", entry.Target), 0)
+			continue
+		}
+
+		item, err := decodeItem(entry.Payload)
+		if err != nil {
+			o.fail(ctx, entry, err, 0)
+			continue
+		}
+
+		if ok, retryAt := o.admit(ctx, entry.Target, item); !ok {
+			logger.Debug("outbox: rate limited", "item_id", entry.ItemID, "target", entry.Target, "retry_at", retryAt)
+			if err := store.Outbox().Defer(ctx, entry.ItemID, entry.Target, entry.Payload, entry.LastError, retryAt); err != nil {
+				logger.Error("outbox: failed to defer delivery", "item_id", entry.ItemID, "target", entry.Target, "error", err)
+			}
+			continue
+		}
+
+		result, err := target.Publish(ctx, item)
+		if err == nil && result.Success {
+			logger.Info("outbox: delivered", "item_id", entry.ItemID, "target", entry.Target, "attempts", entry.Attempts+1)
+			// Batching targets mark the item published once its batch is sent.
+			if _, batched := target.(types.Batcher); !batched {
+				if err := store.Entries().MarkPublished(ctx, entry.ItemID, entry.Target, publishedResult(item, result.Metadata)); err != nil {
+					logger.Error("outbox: failed to mark published", "item_id", entry.ItemID, "target", entry.Target, "error", err)
+					continue
+				}
+			}
+			if err := store.Outbox().Complete(ctx, entry.ItemID, entry.Target); err != nil {
+				logger.Error("outbox: failed to complete delivery", "item_id", entry.ItemID, "target", entry.Target, "error", err)
+			}
+			continue
+		}
+
+		if err == nil {
+			err = fmt.Errorf("publish failed: %v", result.Error)
+		}
+		filters.ReleaseRateLimits(ctx, o.state, item)
+		o.fail(ctx, entry, err, retryAfter(result))
+	}
+
+	return len(entries), nil
+}
+
+// admit takes a slot from each of target's rate limits for item. If one has
+// no room, the slots taken so far are given back.
+func (o *Outbox) admit(ctx context.Context, target string, item *types.Item) (bool, time.Time) {
+	chain, ok := o.chains[target]
+	if !ok {
+		return true, time.Time{}
+	}
+	for _, rl := range chain.RateLimits() {
+		if ok, retryAt := rl.Admit(ctx, o.state, item); !ok {
+			filters.ReleaseRateLimits(ctx, o.state, item)
+			return false, retryAt
+		}
+	}
+	return true, time.Time{}
+}
+
+func (o *Outbox) fail(ctx context.Context, entry storage.OutboxEntry, cause error, wait time.Duration) {
+	logger := o.state.GetLogger()
+	store := o.state.GetStorage().Outbox()
+	attempts := entry.Attempts + 1
+
+	if attempts >= o.maxAttempts {
+		logger.Error("outbox: delivery dead-lettered", "item_id", entry.ItemID, "target", entry.Target, "attempts", attempts, "error", cause)
+		if err := store.Kill(ctx, entry.ItemID, entry.Target, cause.Error()); err != nil {
+			logger.Error("outbox: failed to dead-letter delivery", "item_id", entry.ItemID, "target", entry.Target, "error", err)
+		}
+		return
+	}
+
+	wait = max(wait, o.backoffFor(attempts))
+	logger.Warn("outbox: delivery failed, rescheduling", "item_id", entry.ItemID, "target", entry.Target, "attempts", attempts, "wait_duration", wait, "error", cause)
+	if err := store.Fail(ctx, entry.ItemID, entry.Target, cause.Error(), time.Now().Add(wait)); err != nil {
+		logger.Error("outbox: failed to reschedule delivery", "item_id", entry.ItemID, "target", entry.Target, "error", err)
+	}
+}
+
+func (o *Outbox) backoffFor(attempts int) time.Duration {
+	wait := o.backoff
+	for i := 1; i < attempts && wait < o.maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, o.maxBackoff)
+}
+
+// enqueue records a delivery that failed in the publish cycle so the outbox
+// worker picks it up later.
+func enqueue(ctx context.Context, state types.StateAccessor, target string, item *types.Item, cause error) error {
+	payload, err := json.Marshal(item.Clone())
+	if err != nil {
+		return fmt.Errorf("encode item: %w", err)
+	}
+	backoff := config.ParseDuration(state.GetConfig().Outbox.Backoff, time.Minute)
+	return state.GetStorage().Outbox().Enqueue(ctx, item.ID, target, payload, cause.Error(), time.Now().Add(backoff))
+}
+
+func decodeItem(payload []byte) (*types.Item, error) {
+	var item types.Item
+	if err := json.Unmarshal(payload, &item); err != nil {
+		return nil, fmt.Errorf("decode item: %w", err)
+	}
+	return &item, nil
+}
+
+func retryAfter(result *types.PublishResult) time.Duration {
+	if result == nil || result.Metadata == nil {
+		return 0
+	}
+	if secs, ok := result.Metadata["retry_after"].(float64); ok && secs > 0 {
+		return time.Duration(secs * float64(time.Second))
+	}
+	return 0
+}
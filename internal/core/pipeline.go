@@ -1,6 +1,7 @@
 package core
 
 import (
+	"cartero/internal/processors/filters"
 	"cartero/internal/types"
 	"cartero/internal/utils/batch"
 	"context"
@@ -86,6 +87,11 @@ func (p *Pipeline) Gather(ctx context.Context, state types.StateAccessor) ([]*ty
 			logger.Error("Error processing source", "source", r.Source.Name(), "error", err)
 			return
 		}
+		for _, item := range items {
+			if item.Route == "" {
+				item.Route = r.Source.Name()
+			}
+		}
 		mu.Lock()
 		out = append(out, items...)
 		mu.Unlock()
@@ -94,6 +100,144 @@ func (p *Pipeline) Gather(ctx context.Context, state types.StateAccessor) ([]*ty
 	return out, nil
 }
 
+func (p *Pipeline) TargetsFor(route string) Targets {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	idx, ok := p.routeIndex[route]
+	if !ok {
+		return nil
+	}
+	return p.routes[idx].Targets
+}
+
+func (p *Pipeline) Source(route string) types.Source {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	idx, ok := p.routeIndex[route]
+	if !ok {
+		return nil
+	}
+	return p.routes[idx].Source
+}
+
+func (p *Pipeline) RouteTargetNames() map[string][]string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	out := make(map[string][]string, len(p.routes))
+	for _, route := range p.routes {
+		names := make([]string, 0, len(route.Targets))
+		for _, target := range route.Targets {
+			names = append(names, target.Name())
+		}
+		out[route.Source.Name()] = names
+	}
+	return out
+}
+
+func (p *Pipeline) Publish(ctx context.Context, state types.StateAccessor, items []*types.Item, chains map[string]*filters.Chain, logger *slog.Logger) error {
+	targets := p.AllTargets()
+	due := make(map[string][]*types.Item, len(targets))
+	var shared []*types.Item
+	seen := make(map[*types.Item]bool)
+	for _, target := range targets {
+		routed := pending(ctx, state, target, p.routedTo(target.Name(), items))
+		due[target.Name()] = routed
+		for _, item := range routed {
+			if !seen[item] {
+				seen[item] = true
+				shared = append(shared, item)
+			}
+		}
+	}
+
+	// Each item is stored once, as it left the shared chain and before any
+	// target's chain runs: rate limits defer items to the outbox, whose rows
+	// reference the entry. What a target's chain changes on its copy only
+	// goes into that target's publish result.
+	stored := make(map[*types.Item]bool)
+	for _, item := range storeEntries(ctx, state, shared, logger) {
+		stored[item] = true
+	}
+
+	for _, target := range targets {
+		var routed []*types.Item
+		for _, item := range due[target.Name()] {
+			if stored[item] {
+				routed = append(routed, item)
+			}
+		}
+		if len(routed) == 0 {
+			continue
+		}
+
+		if chain, ok := chains[target.Name()]; ok {
+			clones := make([]*types.Item, len(routed))
+			for i, item := range routed {
+				clones[i] = item.Clone()
+			}
+
+			out, err := chain.Process(ctx, state, clones)
+			if err != nil {
+				logger.Error("publish: target processors failed", "target", target.Name(), "error", err)
+				continue
+			}
+			routed = out
+		}
+
+		if err := (Targets{target}).Publish(ctx, state, routed, logger); err != nil {
+			return err
+		}
+
+		if finalizer, ok := target.(types.Finalizer); ok {
+			if err := finalizer.Finalize(ctx); err != nil {
+				logger.Error("publish: target finalize failed", "target", target.Name(), "error", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (p *Pipeline) routedTo(target string, items []*types.Item) []*types.Item {
+	var out []*types.Item
+	for _, item := range items {
+		for _, t := range p.TargetsFor(item.Route) {
+			if t.Name() == target {
+				out = append(out, item)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// storeEntries persists items and returns the ones that were stored.
+func storeEntries(ctx context.Context, state types.StateAccessor, items []*types.Item, logger *slog.Logger) []*types.Item {
+	store := state.GetStorage()
+	out := make([]*types.Item, 0, len(items))
+	for _, item := range items {
+		if err := store.Entries().Store(ctx, item); err != nil {
+			logger.Error("publish: failed to persist entry", "item_id", item.ID, "error", err)
+			continue
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+func pending(ctx context.Context, state types.StateAccessor, target types.Target, items []*types.Item) []*types.Item {
+	store := state.GetStorage()
+	out := make([]*types.Item, 0, len(items))
+	for _, item := range items {
+		if !isDelivered(ctx, store, item.ID, target) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
 func (p *Pipeline) AllTargets() Targets {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
@@ -118,6 +262,7 @@ func (p *Pipeline) Shutdown(ctx context.Context, logger *slog.Logger) error {
 	defer p.mu.Unlock()
 
 	var errs []error
+	shutdown := make(map[string]bool)
 
 	for _, route := range p.routes {
 		logger.Debug("Shutting down source", "source", route.Source.Name())
@@ -127,6 +272,11 @@ func (p *Pipeline) Shutdown(ctx context.Context, logger *slog.Logger) error {
 		}
 
 		for _, target := range route.Targets {
+			if shutdown[target.Name()] {
+				continue
+			}
+			shutdown[target.Name()] = true
+
 			logger.Debug("Shutting down target", "target", target.Name())
 			if err := target.Shutdown(ctx); err != nil {
 				logger.Error("Error shutting down target", "target", target.Name(), "error", err)
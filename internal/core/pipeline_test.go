@@ -0,0 +1,232 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"cartero/internal/config"
+	"cartero/internal/processors/filters"
+	"cartero/internal/storage"
+	"cartero/internal/types"
+)
+
+// memStorage keeps entries, published results and outbox rows in memory.
+// Like the foreign key on publish_outbox, outbox rows need a stored entry.
+type memStorage struct {
+	mu        sync.Mutex
+	entries   map[string]storage.Item
+	published map[string]map[string]any
+	outbox    map[string]string
+}
+
+func newMemStorage() *memStorage {
+	return &memStorage{
+		entries:   make(map[string]storage.Item),
+		published: make(map[string]map[string]any),
+		outbox:    make(map[string]string),
+	}
+}
+
+func (s *memStorage) Entries() storage.EntryStore     { return memEntries{memStorage: s} }
+func (s *memStorage) Outbox() storage.OutboxStore     { return memOutbox{memStorage: s} }
+func (s *memStorage) Close(ctx context.Context) error { return nil }
+
+// memEntries and memOutbox implement what the publish cycle uses.
+type memEntries struct {
+	storage.EntryStore
+	*memStorage
+}
+
+func (s memEntries) Store(_ context.Context, item storage.Item) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[item.GetID()] = item
+	return nil
+}
+
+func (s memEntries) IsPublished(_ context.Context, itemID, target string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.published[itemID+"/"+target]
+	return ok, nil
+}
+
+func (s memEntries) MarkPublished(_ context.Context, itemID, target string, result map[string]any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.published[itemID+"/"+target] = result
+	return nil
+}
+
+type memOutbox struct {
+	storage.OutboxStore
+	*memStorage
+}
+
+func (s memOutbox) Defer(_ context.Context, itemID, target string, _ []byte, reason string, _ time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[itemID]; !ok {
+		return fmt.Errorf("outbox: entry %s is not stored", itemID)
+	}
+	s.outbox[itemID+"/"+target] = reason
+	return nil
+}
+
+func (s memOutbox) Has(_ context.Context, itemID, target string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.outbox[itemID+"/"+target]
+	return ok, nil
+}
+
+// memLimiter admits limit ids per key, however long ago they were reserved.
+type memLimiter struct {
+	mu    sync.Mutex
+	slots map[string]map[string]bool
+}
+
+func (l *memLimiter) Reserve(_ context.Context, key string, ids []string, limit int, window time.Duration) (int, time.Time, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.slots[key] == nil {
+		l.slots[key] = make(map[string]bool)
+	}
+	allowed := min(max(limit-len(l.slots[key]), 0), len(ids))
+	for _, id := range ids[:allowed] {
+		l.slots[key][id] = true
+	}
+	return allowed, time.Now().Add(window), nil
+}
+
+func (l *memLimiter) Release(_ context.Context, key string, ids ...string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, id := range ids {
+		delete(l.slots[key], id)
+	}
+	return nil
+}
+
+type testState struct {
+	types.StateAccessor
+	storage *memStorage
+	limiter *memLimiter
+}
+
+func (s testState) GetStorage() storage.StorageInterface { return s.storage }
+func (s testState) GetRateLimiter() types.RateLimiter    { return s.limiter }
+func (s testState) GetConfig() *config.Config            { return &config.Config{} }
+func (s testState) GetLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+type stubSource struct {
+	types.Source
+	name string
+}
+
+func (s stubSource) Name() string { return s.name }
+
+type recordingTarget struct {
+	name string
+
+	mu        sync.Mutex
+	published []string
+}
+
+func (t *recordingTarget) Name() string                     { return t.name }
+func (t *recordingTarget) Initialize(context.Context) error { return nil }
+func (t *recordingTarget) Shutdown(context.Context) error   { return nil }
+
+func (t *recordingTarget) Publish(_ context.Context, item *types.Item) (*types.PublishResult, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.published = append(t.published, item.ID)
+	return &types.PublishResult{Success: true, Metadata: map[string]any{"id": item.ID}}, nil
+}
+
+func TestPublishDefersRateLimitedItemsToOutbox(t *testing.T) {
+	ctx := context.Background()
+	state := testState{storage: newMemStorage(), limiter: &memLimiter{slots: make(map[string]map[string]bool)}}
+
+	target := &recordingTarget{name: "mastodon"}
+	pipeline := NewPipeline()
+	pipeline.AddRoute(SourceRoute{Source: stubSource{name: "hackernews"}, Targets: Targets{target}})
+
+	chain, err := filters.NewChain(filters.NewRateLimitFilter("per_minute", target.name, 1, time.Minute))
+	if err != nil {
+		t.Fatalf("NewChain: %v", err)
+	}
+
+	items := []*types.Item{
+		{ID: "first", Title: "First", Route: "hackernews"},
+		{ID: "second", Title: "Second", Route: "hackernews"},
+	}
+	if err := pipeline.Publish(ctx, state, items, map[string]*filters.Chain{target.name: chain}, state.GetLogger()); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	if len(target.published) != 1 || target.published[0] != "first" {
+		t.Errorf("published %v, want only the item within the limit", target.published)
+	}
+	if reason, ok := state.storage.outbox["second/mastodon"]; !ok {
+		t.Errorf("deferred item is not in the outbox: %v", state.storage.outbox)
+	} else if reason != "deferred by per_minute" {
+		t.Errorf("outbox reason = %q", reason)
+	}
+	if _, ok := state.storage.entries["second"]; !ok {
+		t.Error("deferred item was not stored")
+	}
+}
+
+// summarizer sets the summary a target's chain gives its copy of an item.
+type summarizer struct{ summary string }
+
+func (s summarizer) Name() string        { return "summary" }
+func (s summarizer) DependsOn() []string { return nil }
+
+func (s summarizer) Process(_ context.Context, _ types.StateAccessor, items []*types.Item) ([]*types.Item, error) {
+	for _, item := range items {
+		item.AddMetadata("summary", s.summary)
+	}
+	return items, nil
+}
+
+func TestPublishKeepsTargetChainsOutOfStoredEntry(t *testing.T) {
+	ctx := context.Background()
+	state := testState{storage: newMemStorage(), limiter: &memLimiter{slots: make(map[string]map[string]bool)}}
+
+	mastodon, bluesky := &recordingTarget{name: "mastodon"}, &recordingTarget{name: "bluesky"}
+	pipeline := NewPipeline()
+	pipeline.AddRoute(SourceRoute{Source: stubSource{name: "hackernews"}, Targets: Targets{mastodon, bluesky}})
+
+	chains := make(map[string]*filters.Chain)
+	for name, summary := range map[string]string{"mastodon": "toot", "bluesky": "skeet"} {
+		chain, err := filters.NewChain(summarizer{summary: summary})
+		if err != nil {
+			t.Fatalf("NewChain: %v", err)
+		}
+		chains[name] = chain
+	}
+
+	item := &types.Item{ID: "a", Title: "Go release", Route: "hackernews", Metadata: map[string]any{}}
+	if err := pipeline.Publish(ctx, state, []*types.Item{item}, chains, state.GetLogger()); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	stored := state.storage.entries["a"].(*types.Item)
+	if summary := stored.GetSummary(); summary != "" {
+		t.Errorf("stored entry has summary %q from a target's chain", summary)
+	}
+	for target, want := range map[string]string{"mastodon": "toot", "bluesky": "skeet"} {
+		if got := state.storage.published["a/"+target]["summary"]; got != want {
+			t.Errorf("%s result summary = %v, want %q", target, got, want)
+		}
+	}
+}
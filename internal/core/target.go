@@ -1,15 +1,24 @@
 package core
 
 import (
+	"cartero/internal/processors/filters"
+	"cartero/internal/storage"
 	"cartero/internal/types"
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
 	"math"
 	"sync"
 	"time"
 )
 
+// Keys of the per-target item values kept in publish results.
+const (
+	summaryKey = "summary"
+	scoreKey   = "score"
+)
+
 type Targets []types.Target
 
 func (t Targets) Publish(ctx context.Context, state types.StateAccessor, items []*types.Item, logger *slog.Logger) error {
@@ -17,18 +26,13 @@ func (t Targets) Publish(ctx context.Context, state types.StateAccessor, items [
 	for _, item := range items {
 		var pending Targets
 		for _, target := range t {
-			published, _ := store.Entries().IsPublished(ctx, item.ID, target.Name())
-			if !published {
+			if !isDelivered(ctx, store, item.ID, target) {
 				pending = append(pending, target)
 			}
 		}
 		if len(pending) == 0 {
 			continue
 		}
-		if err := store.Entries().Store(ctx, item); err != nil {
-			logger.Error("publish: failed to persist entry", "item_id", item.ID, "error", err)
-			continue
-		}
 		if err := pending.Process(ctx, state, item, logger); err != nil {
 			logger.Error("publish: delivery failed", "item_id", item.ID, "error", err)
 		}
@@ -55,15 +59,25 @@ func (t Targets) Process(ctx context.Context, state types.StateAccessor, item *t
 				}
 			}
 
-			if err := publishWithRetry(ctx, tgt, item, logger); err != nil {
+			result, err := publishWithRetry(ctx, tgt, item, logger)
+			if err != nil {
 				logger.Error("Failed to publish item to target after retries", "item_id", item.ID, "target", tgt.Name(), "error", err)
+				filters.ReleaseRateLimits(context.WithoutCancel(ctx), state, item)
+				if qerr := enqueue(context.WithoutCancel(ctx), state, tgt.Name(), item, err); qerr != nil {
+					logger.Error("Failed to queue delivery for retry", "item_id", item.ID, "target", tgt.Name(), "error", qerr)
+				}
 				errChan <- err
 				return
 			}
 
+			if _, ok := tgt.(types.Batcher); ok {
+				logger.Info("Queued item for batched delivery", "item_id", item.ID, "target", tgt.Name())
+				return
+			}
+
 			logger.Info("Successfully published item to target", "item_id", item.ID, "target", tgt.Name())
 
-			if err := store.Entries().MarkPublished(ctx, item.ID, tgt.Name()); err != nil {
+			if err := store.Entries().MarkPublished(ctx, item.ID, tgt.Name(), publishedResult(item, result.Metadata)); err != nil {
 				logger.Error("Error marking item as published", "item_id", item.ID, "target", tgt.Name(), "error", err)
 				errChan <- err
 			}
@@ -84,7 +98,39 @@ func (t Targets) Process(ctx context.Context, state types.StateAccessor, item *t
 	return nil
 }
 
-func publishWithRetry(ctx context.Context, target types.Target, item *types.Item, logger *slog.Logger) error {
+// publishedResult is what gets stored for a delivery: the target's result
+// plus the summary and score its chain gave the item, which the stored
+// entry doesn't have.
+func publishedResult(item *types.Item, result map[string]any) map[string]any {
+	out := maps.Clone(result)
+	if out == nil {
+		out = make(map[string]any)
+	}
+	if _, ok := out[summaryKey]; !ok {
+		if summary := item.GetSummary(); summary != "" {
+			out[summaryKey] = summary
+		}
+	}
+	if _, ok := out[scoreKey]; !ok {
+		out[scoreKey] = item.GetScore()
+	}
+	return out
+}
+
+// publishedItem returns item as it was delivered to the target whose
+// stored result is published.
+func publishedItem(item *types.Item, published map[string]any) *types.Item {
+	out := item.Clone()
+	if summary, ok := published[summaryKey].(string); ok {
+		out.AddMetadata(summaryKey, summary)
+	}
+	if score, ok := published[scoreKey].(float64); ok {
+		out.SetScore(score)
+	}
+	return out
+}
+
+func publishWithRetry(ctx context.Context, target types.Target, item *types.Item, logger *slog.Logger) (*types.PublishResult, error) {
 	maxRetries := 3
 	var lastErr error
 
@@ -99,7 +145,7 @@ func publishWithRetry(ctx context.Context, target types.Target, item *types.Item
 			if attempt > 0 {
 				logger.Info("Item published successfully on retry", "target", target.Name(), "item_id", item.ID, "attempt", attempt+1)
 			}
-			return nil
+			return result, nil
 		}
 
 		if err != nil {
@@ -111,22 +157,34 @@ func publishWithRetry(ctx context.Context, target types.Target, item *types.Item
 		if attempt < maxRetries {
 			waitDuration := time.Duration(math.Pow(2, float64(attempt))) * time.Second
 
-			if result != nil && result.Metadata != nil {
-				if retryAfter, ok := result.Metadata["retry_after"].(float64); ok && retryAfter > 0 {
-					waitDuration = time.Duration(retryAfter*1000) * time.Millisecond
-				}
+			if wait := retryAfter(result); wait > 0 {
+				waitDuration = wait
 			}
 
 			logger.Warn("Publish attempt failed, retrying", "target", target.Name(), "attempt", attempt+1, "max_attempts", maxRetries+1, "wait_duration", waitDuration, "error", lastErr)
 
 			select {
 			case <-ctx.Done():
-				return ctx.Err()
+				return nil, ctx.Err()
 			case <-time.After(waitDuration):
 				continue
 			}
 		}
 	}
 
-	return fmt.Errorf("target %s: max retries (%d) exceeded: %w", target.Name(), maxRetries+1, lastErr)
+	return nil, fmt.Errorf("target %s: max retries (%d) exceeded: %w", target.Name(), maxRetries+1, lastErr)
+}
+
+// isDelivered reports whether item needs no further delivery to target from
+// the publish cycle: it was published, handed to the outbox or is waiting in
+// a batching target's queue.
+func isDelivered(ctx context.Context, store storage.StorageInterface, itemID string, target types.Target) bool {
+	if published, _ := store.Entries().IsPublished(ctx, itemID, target.Name()); published {
+		return true
+	}
+	if batcher, ok := target.(types.Batcher); ok && batcher.Queued(ctx, itemID) {
+		return true
+	}
+	queued, _ := store.Outbox().Has(ctx, itemID, target.Name())
+	return queued
 }
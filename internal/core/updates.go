@@ -0,0 +1,197 @@
+package core
+
+import (
+	"cartero/internal/config"
+	"cartero/internal/storage"
+	"cartero/internal/types"
+	"context"
+	"maps"
+	"net/url"
+	"slices"
+	"time"
+)
+
+const deletedKey = "deleted"
+
+// Updates re-checks recently published items against their source and
+// propagates title changes and upstream removals to the posts already made.
+// Targets that implement types.Updater are edited in place; targets that
+// only implement types.Deleter have the post removed (on title changes only
+// when delete_on_change is set). Removed items on targets that can only be
+// edited are annotated instead.
+type Updates struct {
+	pipeline       *Pipeline
+	state          types.StateAccessor
+	interval       time.Duration
+	window         time.Duration
+	limit          int
+	deleteOnChange bool
+}
+
+func NewUpdates(pipeline *Pipeline, state types.StateAccessor, cfg config.UpdatesConfig) *Updates {
+	return &Updates{
+		pipeline:       pipeline,
+		state:          state,
+		interval:       config.ParseDuration(cfg.Interval, 30*time.Minute),
+		window:         config.ParseDuration(cfg.Window, 48*time.Hour),
+		limit:          cfg.Limit,
+		deleteOnChange: cfg.DeleteOnChange,
+	}
+}
+
+func (u *Updates) Run(ctx context.Context) {
+	ticker := time.NewTicker(u.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := u.Pass(ctx); err != nil {
+				u.state.GetLogger().Error("updates: pass failed", "error", err)
+			}
+		}
+	}
+}
+
+// Pass re-checks every item published within the window once.
+func (u *Updates) Pass(ctx context.Context) error {
+	logger := u.state.GetLogger()
+
+	entries, err := u.state.GetStorage().Entries().ListPublishedSince(ctx, time.Now().Add(-u.window), u.limit)
+	if err != nil {
+		return err
+	}
+
+	byRoute := make(map[string][]storage.PublishedEntry)
+	for _, e := range entries {
+		byRoute[e.Route] = append(byRoute[e.Route], e)
+	}
+
+	targets := make(map[string]types.Target)
+	for _, target := range u.pipeline.AllTargets() {
+		targets[target.Name()] = target
+	}
+
+	for route, group := range byRoute {
+		rechecker, ok := u.pipeline.Source(route).(types.Rechecker)
+		if !ok {
+			continue
+		}
+
+		items := make([]*types.Item, len(group))
+		for i, e := range group {
+			items[i] = entryItem(e)
+		}
+
+		changed, removed, err := rechecker.Recheck(ctx, items)
+		if err != nil {
+			logger.Warn("updates: recheck failed", "source", route, "error", err)
+			continue
+		}
+		logger.Debug("updates: rechecked source", "source", route, "items", len(items), "changed", len(changed), "removed", len(removed))
+
+		for i, e := range group {
+			item := items[i]
+			switch {
+			case slices.Contains(removed, item.ID):
+				u.propagate(ctx, targets, e, item, true)
+			case slices.Contains(changed, item.ID):
+				u.propagate(ctx, targets, e, item, false)
+				if err := u.state.GetStorage().Entries().SetTitle(ctx, item.ID, item.GetTitle()); err != nil {
+					logger.Error("updates: failed to store new title", "item_id", item.ID, "error", err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (u *Updates) propagate(ctx context.Context, targets map[string]types.Target, e storage.PublishedEntry, item *types.Item, removed bool) {
+	logger := u.state.GetLogger()
+	store := u.state.GetStorage().Entries()
+
+	for _, p := range e.Published {
+		if deleted, _ := p.Result[deletedKey].(bool); deleted {
+			continue
+		}
+		target, ok := targets[p.Target]
+		if !ok {
+			continue
+		}
+
+		updater, canUpdate := target.(types.Updater)
+		deleter, canDelete := target.(types.Deleter)
+
+		result := maps.Clone(p.Result)
+		if result == nil {
+			result = make(map[string]any)
+		}
+		switch {
+		case canDelete && (removed || (!canUpdate && u.deleteOnChange)):
+			if err := deleter.Delete(ctx, item, p.Result); err != nil {
+				logger.Error("updates: failed to delete post", "item_id", item.ID, "target", p.Target, "error", err)
+				continue
+			}
+			result[deletedKey] = true
+			logger.Info("updates: deleted post", "item_id", item.ID, "target", p.Target, "removed", removed)
+
+		case canUpdate:
+			edited := publishedItem(item, p.Result)
+			if removed {
+				edited.SetTitle("[removed] " + item.GetTitle())
+			}
+			res, err := updater.Update(ctx, edited, p.Result)
+			if err != nil {
+				logger.Error("updates: failed to edit post", "item_id", item.ID, "target", p.Target, "error", err)
+				continue
+			}
+			if res != nil {
+				maps.Copy(result, res.Metadata)
+			}
+			logger.Info("updates: edited post", "item_id", item.ID, "target", p.Target, "removed", removed)
+
+		default:
+			continue
+		}
+
+		if err := store.MarkPublished(ctx, item.ID, p.Target, result); err != nil {
+			logger.Error("updates: failed to store publish result", "item_id", item.ID, "target", p.Target, "error", err)
+		}
+	}
+}
+
+// entryItem rebuilds an item from what was stored when it was published.
+// The stored metadata carries fields such as score, comments and summary;
+// the columns win for the fields updates rewrite.
+func entryItem(e storage.PublishedEntry) *types.Item {
+	link, _ := url.Parse(e.Entry.Link)
+
+	metadata := maps.Clone(e.Entry.Metadata)
+	if metadata == nil {
+		metadata = make(map[string]any)
+	}
+	metadata["title"] = e.Entry.Title
+	metadata["description"] = e.Entry.Description
+	metadata["author"] = e.Entry.Author
+
+	item := &types.Item{
+		ID:              e.Entry.ID,
+		Title:           e.Entry.Title,
+		URL:             link,
+		Source:          e.Route,
+		Route:           e.Route,
+		MatchedKeywords: e.Entry.MatchedKeywords,
+		Timestamp:       e.Entry.EntryTimestamp,
+		Metadata:        metadata,
+	}
+	if e.Entry.ImageURL != "" || e.Entry.Description != "" {
+		item.TextContent = &types.Article{
+			Image:       e.Entry.ImageURL,
+			Description: e.Entry.Description,
+		}
+	}
+	return item
+}
@@ -2,6 +2,8 @@ package dag
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 )
 
 type DependencyProvider interface {
@@ -9,9 +11,16 @@ type DependencyProvider interface {
 	GetDependencies() []string
 }
 
+// OrderProvider lets a node break ties between nodes that are ready to run
+// at the same time. Lower orders sort first; nodes without one use 0.
+type OrderProvider interface {
+	GetOrder() int
+}
+
 type node struct {
 	name      string
 	dependsOn []string
+	order     int
 }
 
 type TopologicalSorter struct {
@@ -25,10 +34,14 @@ func NewTopologicalSorter() *TopologicalSorter {
 }
 
 func (ts *TopologicalSorter) AddNode(name string, depProvider DependencyProvider) {
-	ts.nodes[name] = &node{
+	n := &node{
 		name:      name,
 		dependsOn: depProvider.GetDependencies(),
 	}
+	if o, ok := depProvider.(OrderProvider); ok {
+		n.order = o.GetOrder()
+	}
+	ts.nodes[name] = n
 }
 
 func (ts *TopologicalSorter) Sort() ([]string, error) {
@@ -58,6 +71,7 @@ func (ts *TopologicalSorter) Sort() ([]string, error) {
 
 	var result []string
 	for len(queue) > 0 {
+		ts.sortReady(queue)
 		node := queue[0]
 		queue = queue[1:]
 		result = append(result, node)
@@ -71,8 +85,25 @@ func (ts *TopologicalSorter) Sort() ([]string, error) {
 	}
 
 	if len(result) != len(ts.nodes) {
-		return nil, fmt.Errorf("circular dependency detected")
+		var cyclic []string
+		for name, degree := range inDegree {
+			if degree > 0 {
+				cyclic = append(cyclic, name)
+			}
+		}
+		sort.Strings(cyclic)
+		return nil, fmt.Errorf("circular dependency detected between: %s", strings.Join(cyclic, ", "))
 	}
 
 	return result, nil
 }
+
+func (ts *TopologicalSorter) sortReady(queue []string) {
+	sort.Slice(queue, func(i, j int) bool {
+		a, b := ts.nodes[queue[i]], ts.nodes[queue[j]]
+		if a.order != b.order {
+			return a.order < b.order
+		}
+		return a.name < b.name
+	})
+}
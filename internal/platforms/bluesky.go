@@ -91,6 +91,17 @@ func (p *BlueskyPlatform) Do(ctx context.Context, fn func(c *xrpc.Client) error)
 	return err
 }
 
+// DID is the account's DID, or "" before the session exists.
+func (p *BlueskyPlatform) DID() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.client == nil || p.client.Auth == nil {
+		return ""
+	}
+	return p.client.Auth.Did
+}
+
 func (p *BlueskyPlatform) Close(ctx context.Context) error {
 	return nil
 }
@@ -0,0 +1,227 @@
+package platforms
+
+import (
+	"bytes"
+	"cartero/internal/config"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+const defaultMastodonMaxChars = 500
+
+type MastodonPlatform struct {
+	instanceURL string
+	accessToken string
+	client      *http.Client
+	maxChars    int
+}
+
+type MastodonStatusInput struct {
+	Status      string   `json:"status"`
+	MediaIDs    []string `json:"media_ids,omitempty"`
+	Visibility  string   `json:"visibility,omitempty"`
+	Language    string   `json:"language,omitempty"`
+	SpoilerText string   `json:"spoiler_text,omitempty"`
+}
+
+type MastodonStatus struct {
+	ID  string `json:"id"`
+	URI string `json:"uri"`
+	URL string `json:"url"`
+}
+
+type mastodonMedia struct {
+	ID  string  `json:"id"`
+	URL *string `json:"url"`
+}
+
+func NewMastodonPlatform(settings *config.MastodonPlatformSettings) (*MastodonPlatform, error) {
+	if settings.InstanceURL == "" {
+		return nil, fmt.Errorf("mastodon platform: instance_url is required")
+	}
+	if settings.AccessToken == "" {
+		return nil, fmt.Errorf("mastodon platform: access_token is required")
+	}
+
+	return &MastodonPlatform{
+		instanceURL: strings.TrimRight(settings.InstanceURL, "/"),
+		accessToken: settings.AccessToken,
+		client:      &http.Client{Timeout: 60 * time.Second},
+		maxChars:    defaultMastodonMaxChars,
+	}, nil
+}
+
+func (p *MastodonPlatform) Validate() error {
+	return nil
+}
+
+// Initialize checks the access token and reads the instance's status length
+// limit, falling back to Mastodon's default of 500 characters.
+func (p *MastodonPlatform) Initialize(ctx context.Context) error {
+	if err := p.do(ctx, http.MethodGet, "/api/v1/accounts/verify_credentials", nil, "", nil, nil); err != nil {
+		return fmt.Errorf("mastodon platform: failed to verify credentials: %w", err)
+	}
+
+	var instance struct {
+		Configuration struct {
+			Statuses struct {
+				MaxCharacters int `json:"max_characters"`
+			} `json:"statuses"`
+		} `json:"configuration"`
+	}
+	if err := p.do(ctx, http.MethodGet, "/api/v2/instance", nil, "", nil, &instance); err == nil {
+		if n := instance.Configuration.Statuses.MaxCharacters; n > 0 {
+			p.maxChars = n
+		}
+	}
+
+	return nil
+}
+
+func (p *MastodonPlatform) Close(_ context.Context) error {
+	return nil
+}
+
+func (p *MastodonPlatform) MaxChars() int {
+	return p.maxChars
+}
+
+// PostStatus creates a status. The idempotency key makes retries of the same
+// item return the original status instead of posting twice.
+func (p *MastodonPlatform) PostStatus(ctx context.Context, input MastodonStatusInput, idempotencyKey string) (*MastodonStatus, error) {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("mastodon: marshal status: %w", err)
+	}
+
+	headers := map[string]string{}
+	if idempotencyKey != "" {
+		headers["Idempotency-Key"] = idempotencyKey
+	}
+
+	var status MastodonStatus
+	if err := p.do(ctx, http.MethodPost, "/api/v1/statuses", bytes.NewReader(body), "application/json", headers, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (p *MastodonPlatform) EditStatus(ctx context.Context, id string, input MastodonStatusInput) (*MastodonStatus, error) {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("mastodon: marshal status: %w", err)
+	}
+
+	var status MastodonStatus
+	if err := p.do(ctx, http.MethodPut, "/api/v1/statuses/"+id, bytes.NewReader(body), "application/json", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (p *MastodonPlatform) DeleteStatus(ctx context.Context, id string) error {
+	return p.do(ctx, http.MethodDelete, "/api/v1/statuses/"+id, nil, "", nil, nil)
+}
+
+// UploadMedia uploads an attachment with alt text and waits briefly for the
+// instance to finish processing it, since unprocessed media can't be attached.
+func (p *MastodonPlatform) UploadMedia(ctx context.Context, data []byte, filename, contentType, description string) (string, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, filename))
+	h.Set("Content-Type", contentType)
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return "", fmt.Errorf("mastodon: create media part: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", fmt.Errorf("mastodon: write media part: %w", err)
+	}
+	if description != "" {
+		if err := w.WriteField("description", description); err != nil {
+			return "", fmt.Errorf("mastodon: write description: %w", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("mastodon: close multipart: %w", err)
+	}
+
+	var media mastodonMedia
+	if err := p.do(ctx, http.MethodPost, "/api/v2/media", &buf, w.FormDataContentType(), nil, &media); err != nil {
+		return "", err
+	}
+
+	for attempt := 0; media.URL == nil && attempt < 5; attempt++ {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(time.Second):
+		}
+		if err := p.do(ctx, http.MethodGet, "/api/v1/media/"+media.ID, nil, "", nil, &media); err != nil {
+			return "", err
+		}
+	}
+
+	return media.ID, nil
+}
+
+func (p *MastodonPlatform) do(ctx context.Context, method, path string, body io.Reader, contentType string, headers map[string]string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, p.instanceURL+path, body)
+	if err != nil {
+		return fmt.Errorf("mastodon: create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.accessToken)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("mastodon: %s %s: %w", method, path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return &RateLimitError{Platform: "mastodon", RetryAfter: mastodonRetryAfter(resp.Header)}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("mastodon: %s %s: status %d: %s", method, path, resp.StatusCode, string(b))
+	}
+
+	// 206 is returned while media is still being processed.
+	if out == nil || resp.StatusCode == http.StatusPartialContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("mastodon: decode response: %w", err)
+	}
+	return nil
+}
+
+// mastodonRetryAfter reads Retry-After, or Mastodon's X-RateLimit-Reset
+// timestamp when that's all the instance sends.
+func mastodonRetryAfter(h http.Header) float64 {
+	if secs := ParseRetryAfter(h.Get("Retry-After")); secs > 0 {
+		return secs
+	}
+	if reset, err := time.Parse(time.RFC3339, h.Get("X-RateLimit-Reset")); err == nil {
+		if wait := time.Until(reset).Seconds(); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
@@ -0,0 +1,252 @@
+package platforms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"cartero/internal/config"
+)
+
+const testMastodonToken = "secret-token"
+
+// fakeMastodon is an instance that keeps posted statuses and checks the
+// access token on every request.
+type fakeMastodon struct {
+	mu        sync.Mutex
+	statuses  map[string]MastodonStatusInput
+	keys      map[string]string
+	media     map[string]string
+	rateLimit http.Header
+}
+
+func newFakeMastodon(t *testing.T) (*fakeMastodon, *MastodonPlatform) {
+	f := &fakeMastodon{
+		statuses: make(map[string]MastodonStatusInput),
+		keys:     make(map[string]string),
+		media:    make(map[string]string),
+	}
+
+	srv := httptest.NewServer(f.handler())
+	t.Cleanup(srv.Close)
+
+	p, err := NewMastodonPlatform(&config.MastodonPlatformSettings{InstanceURL: srv.URL + "/", AccessToken: testMastodonToken})
+	if err != nil {
+		t.Fatalf("NewMastodonPlatform: %v", err)
+	}
+	return f, p
+}
+
+func (f *fakeMastodon) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/v1/accounts/verify_credentials", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"id":"1","username":"cartero"}`)
+	})
+	mux.HandleFunc("GET /api/v2/instance", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"configuration":{"statuses":{"max_characters":1000}}}`)
+	})
+	mux.HandleFunc("POST /api/v1/statuses", func(w http.ResponseWriter, r *http.Request) {
+		var input MastodonStatusInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		// Mastodon answers a repeated Idempotency-Key with the first status.
+		key := r.Header.Get("Idempotency-Key")
+		id, seen := f.keys[key]
+		if !seen || key == "" {
+			id = string(rune('1' + len(f.statuses)))
+			f.statuses[id] = input
+			f.keys[key] = id
+		}
+		writeStatus(w, id)
+	})
+	mux.HandleFunc("PUT /api/v1/statuses/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var input MastodonStatusInput
+		_ = json.NewDecoder(r.Body).Decode(&input)
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		id := r.PathValue("id")
+		if _, ok := f.statuses[id]; !ok {
+			http.Error(w, `{"error":"Record not found"}`, http.StatusNotFound)
+			return
+		}
+		f.statuses[id] = input
+		writeStatus(w, id)
+	})
+	mux.HandleFunc("POST /api/v2/media", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		if _, _, err := r.FormFile("file"); err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		f.mu.Lock()
+		f.media["m1"] = r.FormValue("description")
+		f.mu.Unlock()
+
+		// Still processing: no URL yet.
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = io.WriteString(w, `{"id":"m1","url":null}`)
+	})
+	mux.HandleFunc("GET /api/v1/media/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"id":"`+r.PathValue("id")+`","url":"https://files.example/m1.png"}`)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testMastodonToken {
+			http.Error(w, `{"error":"The access token is invalid"}`, http.StatusUnauthorized)
+			return
+		}
+
+		f.mu.Lock()
+		limited := f.rateLimit
+		f.mu.Unlock()
+		if limited != nil {
+			for k, v := range limited {
+				w.Header()[k] = v
+			}
+			http.Error(w, `{"error":"Too many requests"}`, http.StatusTooManyRequests)
+			return
+		}
+
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func writeStatus(w http.ResponseWriter, id string) {
+	_ = json.NewEncoder(w).Encode(MastodonStatus{
+		ID:  id,
+		URI: "https://social.example/users/cartero/statuses/" + id,
+		URL: "https://social.example/@cartero/" + id,
+	})
+}
+
+func TestMastodonInitializeReadsMaxChars(t *testing.T) {
+	_, p := newFakeMastodon(t)
+
+	if err := p.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if p.MaxChars() != 1000 {
+		t.Errorf("MaxChars = %d, want the instance's 1000", p.MaxChars())
+	}
+}
+
+func TestMastodonInitializeRejectsBadToken(t *testing.T) {
+	_, p := newFakeMastodon(t)
+	p.accessToken = "wrong"
+
+	if err := p.Initialize(context.Background()); err == nil {
+		t.Fatal("Initialize succeeded with a bad token")
+	}
+}
+
+func TestMastodonPostStatusIsIdempotent(t *testing.T) {
+	f, p := newFakeMastodon(t)
+	ctx := context.Background()
+	input := MastodonStatusInput{Status: "Hello", Visibility: "unlisted", Language: "en"}
+
+	first, err := p.PostStatus(ctx, input, "mastodon:item-1")
+	if err != nil {
+		t.Fatalf("PostStatus: %v", err)
+	}
+	retry, err := p.PostStatus(ctx, input, "mastodon:item-1")
+	if err != nil {
+		t.Fatalf("PostStatus retry: %v", err)
+	}
+
+	if first.ID != retry.ID {
+		t.Errorf("retry posted status %s, want the original %s", retry.ID, first.ID)
+	}
+	if len(f.statuses) != 1 {
+		t.Fatalf("instance has %d statuses, want 1", len(f.statuses))
+	}
+	if got := f.statuses[first.ID]; !reflect.DeepEqual(got, input) {
+		t.Errorf("posted %+v, want %+v", got, input)
+	}
+}
+
+func TestMastodonEditStatus(t *testing.T) {
+	f, p := newFakeMastodon(t)
+	ctx := context.Background()
+
+	posted, err := p.PostStatus(ctx, MastodonStatusInput{Status: "Old title"}, "")
+	if err != nil {
+		t.Fatalf("PostStatus: %v", err)
+	}
+	if _, err := p.EditStatus(ctx, posted.ID, MastodonStatusInput{Status: "New title"}); err != nil {
+		t.Fatalf("EditStatus: %v", err)
+	}
+	if got := f.statuses[posted.ID].Status; got != "New title" {
+		t.Errorf("status text = %q after edit", got)
+	}
+
+	if _, err := p.EditStatus(ctx, "missing", MastodonStatusInput{Status: "x"}); err == nil {
+		t.Error("EditStatus of a missing status succeeded")
+	}
+}
+
+func TestMastodonUploadMediaWaitsForProcessing(t *testing.T) {
+	f, p := newFakeMastodon(t)
+
+	id, err := p.UploadMedia(context.Background(), []byte("\x89PNG"), "thumb.png", "image/png", "A thumbnail")
+	if err != nil {
+		t.Fatalf("UploadMedia: %v", err)
+	}
+	if id != "m1" {
+		t.Errorf("media ID = %q, want m1", id)
+	}
+	if f.media["m1"] != "A thumbnail" {
+		t.Errorf("alt text = %q, want it sent as description", f.media["m1"])
+	}
+}
+
+func TestMastodonRateLimit(t *testing.T) {
+	reset := time.Now().Add(90 * time.Second).UTC()
+
+	tests := []struct {
+		name   string
+		header http.Header
+		min    float64
+		max    float64
+	}{
+		{"retry after seconds", http.Header{"Retry-After": {"30"}}, 30, 30},
+		{"retry after date", http.Header{"Retry-After": {reset.Format(http.TimeFormat)}}, 80, 90},
+		{"rate limit reset", http.Header{"X-Ratelimit-Reset": {reset.Format(time.RFC3339)}}, 80, 90},
+		{"no hint", http.Header{}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, p := newFakeMastodon(t)
+			f.rateLimit = tt.header
+
+			_, err := p.PostStatus(context.Background(), MastodonStatusInput{Status: "Hello"}, "")
+
+			var rateLimit *RateLimitError
+			if !errors.As(err, &rateLimit) {
+				t.Fatalf("error = %v, want a RateLimitError", err)
+			}
+			if rateLimit.RetryAfter < tt.min || rateLimit.RetryAfter > tt.max {
+				t.Errorf("RetryAfter = %v, want between %v and %v", rateLimit.RetryAfter, tt.min, tt.max)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package platforms
+
+import (
+	"bytes"
+	"cartero/internal/config"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type MatrixPlatform struct {
+	homeserverURL string
+	accessToken   string
+	client        *http.Client
+	userID        string
+}
+
+type matrixError struct {
+	ErrCode      string  `json:"errcode"`
+	Error        string  `json:"error"`
+	RetryAfterMS float64 `json:"retry_after_ms"`
+}
+
+func NewMatrixPlatform(settings *config.MatrixPlatformSettings) (*MatrixPlatform, error) {
+	if settings.HomeserverURL == "" {
+		return nil, fmt.Errorf("matrix platform: homeserver_url is required")
+	}
+	if settings.AccessToken == "" {
+		return nil, fmt.Errorf("matrix platform: access_token is required")
+	}
+
+	return &MatrixPlatform{
+		homeserverURL: strings.TrimRight(settings.HomeserverURL, "/"),
+		accessToken:   settings.AccessToken,
+		client:        &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+func (p *MatrixPlatform) Validate() error {
+	return nil
+}
+
+// Initialize checks the access token against the homeserver.
+func (p *MatrixPlatform) Initialize(ctx context.Context) error {
+	var whoami struct {
+		UserID string `json:"user_id"`
+	}
+	if err := p.do(ctx, http.MethodGet, "/_matrix/client/v3/account/whoami", nil, "", &whoami); err != nil {
+		return fmt.Errorf("matrix platform: failed to verify access token: %w", err)
+	}
+	p.userID = whoami.UserID
+	return nil
+}
+
+func (p *MatrixPlatform) Close(_ context.Context) error {
+	return nil
+}
+
+func (p *MatrixPlatform) UserID() string {
+	return p.userID
+}
+
+// SendMessage sends an m.room.message event. The homeserver returns the
+// original event for a repeated transaction ID instead of sending it again.
+func (p *MatrixPlatform) SendMessage(ctx context.Context, roomID, txnID string, content any) (string, error) {
+	body, err := json.Marshal(content)
+	if err != nil {
+		return "", fmt.Errorf("matrix: marshal event: %w", err)
+	}
+
+	path := "/_matrix/client/v3/rooms/" + url.PathEscape(roomID) + "/send/m.room.message/" + url.PathEscape(txnID)
+
+	var resp struct {
+		EventID string `json:"event_id"`
+	}
+	if err := p.do(ctx, http.MethodPut, path, bytes.NewReader(body), "application/json", &resp); err != nil {
+		return "", err
+	}
+	return resp.EventID, nil
+}
+
+func (p *MatrixPlatform) Redact(ctx context.Context, roomID, eventID, txnID, reason string) error {
+	body, err := json.Marshal(map[string]string{"reason": reason})
+	if err != nil {
+		return fmt.Errorf("matrix: marshal redaction: %w", err)
+	}
+
+	path := "/_matrix/client/v3/rooms/" + url.PathEscape(roomID) + "/redact/" + url.PathEscape(eventID) + "/" + url.PathEscape(txnID)
+	return p.do(ctx, http.MethodPut, path, bytes.NewReader(body), "application/json", nil)
+}
+
+// Upload stores data in the media repository and returns its mxc:// URI.
+func (p *MatrixPlatform) Upload(ctx context.Context, data []byte, filename, contentType string) (string, error) {
+	path := "/_matrix/media/v3/upload?filename=" + url.QueryEscape(filename)
+
+	var resp struct {
+		ContentURI string `json:"content_uri"`
+	}
+	if err := p.do(ctx, http.MethodPost, path, bytes.NewReader(data), contentType, &resp); err != nil {
+		return "", err
+	}
+	return resp.ContentURI, nil
+}
+
+func (p *MatrixPlatform) do(ctx context.Context, method, path string, body io.Reader, contentType string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, p.homeserverURL+path, body)
+	if err != nil {
+		return fmt.Errorf("matrix: create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.accessToken)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("matrix: %s %s: %w", method, path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		var merr matrixError
+		_ = json.Unmarshal(b, &merr)
+
+		if resp.StatusCode == http.StatusTooManyRequests || merr.ErrCode == "M_LIMIT_EXCEEDED" {
+			return &RateLimitError{Platform: "matrix", RetryAfter: matrixRetryAfter(resp.Header, merr)}
+		}
+		if merr.ErrCode != "" {
+			return fmt.Errorf("matrix: %s %s: %s: %s", method, path, merr.ErrCode, merr.Error)
+		}
+		return fmt.Errorf("matrix: %s %s: status %d: %s", method, path, resp.StatusCode, string(b))
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("matrix: decode response: %w", err)
+	}
+	return nil
+}
+
+// matrixRetryAfter prefers the Retry-After header, which newer homeservers
+// send, over the retry_after_ms field of the error body.
+func matrixRetryAfter(h http.Header, merr matrixError) float64 {
+	if secs := ParseRetryAfter(h.Get("Retry-After")); secs > 0 {
+		return secs
+	}
+	return merr.RetryAfterMS / 1000
+}
@@ -0,0 +1,223 @@
+package platforms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"cartero/internal/config"
+)
+
+const testMatrixToken = "syt_secret"
+
+// fakeHomeserver records sent events by transaction ID and uploaded media,
+// and answers with M_LIMIT_EXCEEDED while limited is set.
+type fakeHomeserver struct {
+	mu      sync.Mutex
+	events  map[string]map[string]any
+	txns    map[string]string
+	uploads map[string][]byte
+	limited func(w http.ResponseWriter)
+}
+
+func newFakeHomeserver(t *testing.T) (*fakeHomeserver, *MatrixPlatform) {
+	f := &fakeHomeserver{
+		events:  make(map[string]map[string]any),
+		txns:    make(map[string]string),
+		uploads: make(map[string][]byte),
+	}
+
+	srv := httptest.NewServer(f.handler())
+	t.Cleanup(srv.Close)
+
+	p, err := NewMatrixPlatform(&config.MatrixPlatformSettings{HomeserverURL: srv.URL + "/", AccessToken: testMatrixToken})
+	if err != nil {
+		t.Fatalf("NewMatrixPlatform: %v", err)
+	}
+	return f, p
+}
+
+func (f *fakeHomeserver) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /_matrix/client/v3/account/whoami", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"user_id":"@cartero:example.org"}`)
+	})
+	mux.HandleFunc("PUT /_matrix/client/v3/rooms/{room}/send/m.room.message/{txn}", func(w http.ResponseWriter, r *http.Request) {
+		var content map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&content); err != nil {
+			writeMatrixError(w, http.StatusBadRequest, "M_NOT_JSON", err.Error())
+			return
+		}
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		// A repeated transaction ID returns the event it created.
+		txn := r.PathValue("room") + "/" + r.PathValue("txn")
+		id, seen := f.txns[txn]
+		if !seen {
+			id = "$event" + strconv.Itoa(len(f.events)+1)
+			f.events[id] = content
+			f.txns[txn] = id
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"event_id": id})
+	})
+	mux.HandleFunc("POST /_matrix/media/v3/upload", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") == "" {
+			writeMatrixError(w, http.StatusBadRequest, "M_MISSING_PARAM", "missing content type")
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+
+		f.mu.Lock()
+		f.uploads[r.URL.Query().Get("filename")] = data
+		f.mu.Unlock()
+
+		_, _ = io.WriteString(w, `{"content_uri":"mxc://example.org/abc123"}`)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testMatrixToken {
+			writeMatrixError(w, http.StatusUnauthorized, "M_UNKNOWN_TOKEN", "Invalid access token passed.")
+			return
+		}
+
+		f.mu.Lock()
+		limited := f.limited
+		f.mu.Unlock()
+		if limited != nil {
+			limited(w)
+			return
+		}
+
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func writeMatrixError(w http.ResponseWriter, status int, code, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"errcode": code, "error": msg})
+}
+
+func TestMatrixInitializeReadsUserID(t *testing.T) {
+	_, p := newFakeHomeserver(t)
+
+	if err := p.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if p.UserID() != "@cartero:example.org" {
+		t.Errorf("UserID = %q", p.UserID())
+	}
+}
+
+func TestMatrixInitializeRejectsBadToken(t *testing.T) {
+	_, p := newFakeHomeserver(t)
+	p.accessToken = "wrong"
+
+	err := p.Initialize(context.Background())
+	if err == nil {
+		t.Fatal("Initialize succeeded with a bad token")
+	}
+	if !strings.Contains(err.Error(), "M_UNKNOWN_TOKEN") {
+		t.Errorf("error = %v, want the homeserver's errcode", err)
+	}
+}
+
+func TestMatrixSendMessageReusesTransaction(t *testing.T) {
+	f, p := newFakeHomeserver(t)
+	ctx := context.Background()
+	content := map[string]any{"msgtype": "m.text", "body": "Hello"}
+
+	first, err := p.SendMessage(ctx, "!room:example.org", "item-1", content)
+	if err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	retry, err := p.SendMessage(ctx, "!room:example.org", "item-1", content)
+	if err != nil {
+		t.Fatalf("SendMessage retry: %v", err)
+	}
+
+	if first != retry {
+		t.Errorf("retry sent event %s, want the original %s", retry, first)
+	}
+	if len(f.events) != 1 {
+		t.Fatalf("room has %d events, want 1", len(f.events))
+	}
+	if f.events[first]["body"] != "Hello" {
+		t.Errorf("event content = %v", f.events[first])
+	}
+}
+
+func TestMatrixUpload(t *testing.T) {
+	f, p := newFakeHomeserver(t)
+
+	uri, err := p.Upload(context.Background(), []byte("\x89PNG"), "thumb image.png", "image/png")
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if uri != "mxc://example.org/abc123" {
+		t.Errorf("content URI = %q", uri)
+	}
+	if string(f.uploads["thumb image.png"]) != "\x89PNG" {
+		t.Errorf("uploads = %v, want the data under its filename", f.uploads)
+	}
+}
+
+func TestMatrixRateLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		limited func(w http.ResponseWriter)
+		want    float64
+	}{
+		{
+			name: "retry after header",
+			limited: func(w http.ResponseWriter) {
+				w.Header().Set("Retry-After", "12")
+				w.WriteHeader(http.StatusTooManyRequests)
+				_, _ = io.WriteString(w, `{"errcode":"M_LIMIT_EXCEEDED","error":"Too many requests","retry_after_ms":2000}`)
+			},
+			want: 12,
+		},
+		{
+			name: "retry_after_ms body",
+			limited: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusTooManyRequests)
+				_, _ = io.WriteString(w, `{"errcode":"M_LIMIT_EXCEEDED","error":"Too many requests","retry_after_ms":2500}`)
+			},
+			want: 2.5,
+		},
+		{
+			name: "errcode without 429",
+			limited: func(w http.ResponseWriter) {
+				writeMatrixError(w, http.StatusBadRequest, "M_LIMIT_EXCEEDED", "Too many requests")
+			},
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, p := newFakeHomeserver(t)
+			f.limited = tt.limited
+
+			_, err := p.SendMessage(context.Background(), "!room:example.org", "item-1", map[string]any{"body": "Hello"})
+
+			var rateLimit *RateLimitError
+			if !errors.As(err, &rateLimit) {
+				t.Fatalf("error = %v, want a RateLimitError", err)
+			}
+			if rateLimit.RetryAfter != tt.want {
+				t.Errorf("RetryAfter = %v, want %v", rateLimit.RetryAfter, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package platforms
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimitError is returned by platform clients and targets when the
+// remote API asks us to slow down. RetryAfter is in seconds and may be 0 if
+// unknown.
+type RateLimitError struct {
+	Platform   string
+	RetryAfter float64
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s: rate limited, retry after %.0fs", e.Platform, e.RetryAfter)
+}
+
+// ParseRetryAfter returns the wait in seconds from a Retry-After header,
+// which is either a number of seconds or an HTTP date. It returns 0 when
+// the header is missing or malformed.
+func ParseRetryAfter(value string) float64 {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.ParseFloat(value, 64); err == nil && secs > 0 {
+		return secs
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(at).Seconds(); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
@@ -16,11 +16,12 @@ const (
 
 type ExtractText struct {
 	settings  config.ExtractTextSettings
+	limit     int
 	extractor Extractor
 }
 
-func NewExtractProcessor(settings config.ExtractTextSettings) *ExtractText {
-	return &ExtractText{settings: settings, extractor: newExtractor(settings)}
+func NewExtractProcessor(settings config.ExtractTextSettings, limit int) *ExtractText {
+	return &ExtractText{settings: settings, limit: limit, extractor: newExtractor(settings)}
 }
 
 func (e *ExtractText) Name() string {
@@ -51,6 +52,12 @@ func (e *ExtractText) Process(ctx context.Context, st types.StateAccessor, items
 func (e *ExtractText) extract(ctx context.Context, st types.StateAccessor, item *types.Item) {
 	logger := st.GetLogger()
 
+	if item.KeepsText() {
+		if article := item.GetArticle(); article != nil && len(article.Text) >= e.settings.MinContentLength {
+			return
+		}
+	}
+
 	u := item.GetURL()
 	if u == nil || u.String() == "" {
 		return
@@ -61,7 +68,7 @@ func (e *ExtractText) extract(ctx context.Context, st types.StateAccessor, item
 		timeout = defaultExtractTimeout
 	}
 
-	article, err := e.extractor.Extract(ctx, u, e.settings.Limit, timeout)
+	article, err := e.extractor.Extract(ctx, u, e.limit, timeout)
 	if err != nil {
 		logger.Error("ExtractText processor failed to extract article text", "processor", names.ExtractText, "item_id", item.ID, "error", err)
 		return
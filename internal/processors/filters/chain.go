@@ -2,17 +2,20 @@ package filters
 
 import (
 	"context"
+	"fmt"
 
 	"cartero/internal/dag"
+	"cartero/internal/processors/names"
 	"cartero/internal/types"
 )
 
 const (
-	filterPublishedDedupe = "published_dedupe"
-	filterRank            = "rank"
-	filterRerank          = "rerank"
-	filterDiversify       = "diversify"
-	filterLimit           = "limit"
+	filterPublishedDedupe = names.PublishedDedupe
+	filterRank            = names.Rank
+	filterRerank          = names.Rerank
+	filterDiversify       = names.Diversify
+	filterLimit           = names.Limit
+	filterMinScore        = names.MinScore
 )
 
 type Processor interface {
@@ -21,26 +24,111 @@ type Processor interface {
 	Process(ctx context.Context, state types.StateAccessor, items []*types.Item) ([]*types.Item, error)
 }
 
+type configured struct {
+	Processor
+	extra    []string
+	declared []string
+	override bool
+	order    int
+}
+
+func wrap(p Processor) *configured {
+	if c, ok := p.(*configured); ok {
+		return c
+	}
+	return &configured{Processor: p}
+}
+
+func (c *configured) DependsOn() []string {
+	var deps []string
+	if !c.override {
+		deps = append(deps, c.Processor.DependsOn()...)
+	}
+	deps = append(deps, c.declared...)
+	return append(deps, c.extra...)
+}
+
+func (c *configured) Order() int { return c.order }
+
+// After returns p with deps appended to its own dependencies.
+func After(p Processor, deps ...string) Processor {
+	if len(deps) == 0 {
+		return p
+	}
+	c := wrap(p)
+	c.extra = append(c.extra, deps...)
+	return c
+}
+
+// Configure applies the depends_on and order declared for p in the config.
+// Declared dependencies extend the built-in ones unless override is set,
+// and must name processors that are part of the chain.
+func Configure(p Processor, dependsOn []string, override bool, order int) Processor {
+	if len(dependsOn) == 0 && !override && order == 0 {
+		return p
+	}
+	c := wrap(p)
+	c.declared = dependsOn
+	c.override = override
+	c.order = order
+	return c
+}
+
 type Chain struct {
 	processors map[string]Processor
 	order      []string
 }
 
-func NewChain(ps ...Processor) *Chain {
+func NewChain(ps ...Processor) (*Chain, error) {
 	c := &Chain{processors: make(map[string]Processor, len(ps))}
 	for _, p := range ps {
 		c.processors[p.Name()] = p
 	}
+	for _, p := range ps {
+		cp, ok := p.(*configured)
+		if !ok {
+			continue
+		}
+		for _, dep := range cp.declared {
+			if _, exists := c.processors[dep]; !exists {
+				return nil, fmt.Errorf("processor chain: %s depends on %s, which is not enabled", p.Name(), dep)
+			}
+		}
+	}
 	sorter := dag.NewTopologicalSorter()
 	for _, p := range c.processors {
 		sorter.AddNode(p.Name(), procNode{p})
 	}
 	order, err := sorter.Sort()
 	if err != nil {
-		panic("processor chain: " + err.Error())
+		return nil, fmt.Errorf("processor chain: %w", err)
 	}
 	c.order = order
-	return c
+	return c, nil
+}
+
+func (c *Chain) Order() []string {
+	return c.order
+}
+
+func (c *Chain) Has(name string) bool {
+	_, ok := c.processors[name]
+	return ok
+}
+
+// RateLimits returns the chain's rate limits in the order they run.
+func (c *Chain) RateLimits() []*RateLimitFilter {
+	var out []*RateLimitFilter
+	for _, name := range c.order {
+		p := c.processors[name]
+		if cp, ok := p.(*configured); ok {
+			p = cp.Processor
+		}
+		if rl, ok := p.(*RateLimitFilter); ok {
+			out = append(out, rl)
+		}
+	}
+	return out
 }
 
 func (c *Chain) Process(ctx context.Context, state types.StateAccessor, items []*types.Item) ([]*types.Item, error) {
@@ -61,3 +149,10 @@ type procNode struct{ p Processor }
 
 func (n procNode) GetName() string           { return n.p.Name() }
 func (n procNode) GetDependencies() []string { return n.p.DependsOn() }
+
+func (n procNode) GetOrder() int {
+	if o, ok := n.p.(interface{ Order() int }); ok {
+		return o.Order()
+	}
+	return 0
+}
@@ -13,7 +13,7 @@ type DiversifyFilter struct{}
 func NewDiversifyFilter() *DiversifyFilter { return &DiversifyFilter{} }
 
 func (f *DiversifyFilter) Name() string        { return filterDiversify }
-func (f *DiversifyFilter) DependsOn() []string { return []string{filterRank} }
+func (f *DiversifyFilter) DependsOn() []string { return []string{filterRank, filterRerank} }
 
 func (f *DiversifyFilter) Process(ctx context.Context, state types.StateAccessor, items []*types.Item) ([]*types.Item, error) {
 	if len(items) < 2 {
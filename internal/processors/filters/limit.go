@@ -0,0 +1,26 @@
+package filters
+
+import (
+	"context"
+
+	"cartero/internal/types"
+)
+
+type LimitFilter struct {
+	limit int
+}
+
+func NewLimitFilter(limit int) *LimitFilter {
+	return &LimitFilter{limit: limit}
+}
+
+func (f *LimitFilter) Name() string        { return filterLimit }
+func (f *LimitFilter) DependsOn() []string { return []string{filterDiversify, filterMinScore} }
+
+func (f *LimitFilter) Process(ctx context.Context, state types.StateAccessor, items []*types.Item) ([]*types.Item, error) {
+	if f.limit <= 0 || len(items) <= f.limit {
+		return items, nil
+	}
+	state.GetLogger().Debug("limit: dropped items", "limit", f.limit, "dropped", len(items)-f.limit)
+	return items[:f.limit], nil
+}
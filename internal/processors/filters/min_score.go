@@ -0,0 +1,31 @@
+package filters
+
+import (
+	"context"
+
+	"cartero/internal/types"
+)
+
+type MinScoreFilter struct {
+	minScore float64
+}
+
+func NewMinScoreFilter(minScore float64) *MinScoreFilter {
+	return &MinScoreFilter{minScore: minScore}
+}
+
+func (f *MinScoreFilter) Name() string        { return filterMinScore }
+func (f *MinScoreFilter) DependsOn() []string { return []string{filterDiversify} }
+
+func (f *MinScoreFilter) Process(ctx context.Context, state types.StateAccessor, items []*types.Item) ([]*types.Item, error) {
+	logger := state.GetLogger()
+	out := make([]*types.Item, 0, len(items))
+	for _, item := range items {
+		if score := item.GetScore(); score < f.minScore {
+			logger.Debug("min_score: dropped item", "item_id", item.ID, "score", score, "min_score", f.minScore)
+			continue
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
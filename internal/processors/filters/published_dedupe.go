@@ -8,25 +8,25 @@ import (
 )
 
 type PublishedDedupeFilter struct {
-	targets []string
+	routes map[string][]string
 }
 
-func NewPublishedDedupeFilter(targets []string) *PublishedDedupeFilter {
-	return &PublishedDedupeFilter{targets: targets}
+func NewPublishedDedupeFilter(routes map[string][]string) *PublishedDedupeFilter {
+	return &PublishedDedupeFilter{routes: routes}
 }
 
 func (f *PublishedDedupeFilter) Name() string        { return filterPublishedDedupe }
 func (f *PublishedDedupeFilter) DependsOn() []string { return []string{names.Blocklist} }
 
 func (f *PublishedDedupeFilter) Process(ctx context.Context, state types.StateAccessor, items []*types.Item) ([]*types.Item, error) {
-	if len(f.targets) == 0 {
+	if len(f.routes) == 0 {
 		return items, nil
 	}
 	store := state.GetStorage()
 	out := make([]*types.Item, 0, len(items))
 	for _, item := range items {
 		delivered := true
-		for _, target := range f.targets {
+		for _, target := range f.routes[item.Route] {
 			published, _ := store.Entries().IsPublished(ctx, item.ID, target)
 			if !published {
 				delivered = false
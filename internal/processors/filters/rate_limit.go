@@ -0,0 +1,113 @@
+package filters
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"cartero/internal/types"
+)
+
+// RateLimitFilter lets through as many items as target may still publish in
+// the window. The rest are deferred to the outbox, which offers them to the
+// limiter again once a slot frees up.
+type RateLimitFilter struct {
+	name   string
+	target string
+	key    string
+	limit  int
+	window time.Duration
+}
+
+func NewRateLimitFilter(name, target string, limit int, window time.Duration) *RateLimitFilter {
+	return &RateLimitFilter{
+		name:   name,
+		target: target,
+		key:    target + ":" + name,
+		limit:  limit,
+		window: window,
+	}
+}
+
+func (f *RateLimitFilter) Name() string        { return f.name }
+func (f *RateLimitFilter) DependsOn() []string { return []string{filterLimit} }
+
+func (f *RateLimitFilter) Process(ctx context.Context, state types.StateAccessor, items []*types.Item) ([]*types.Item, error) {
+	rl := state.GetRateLimiter()
+	if rl == nil || f.limit <= 0 || len(items) == 0 {
+		return items, nil
+	}
+
+	logger := state.GetLogger()
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+
+	allowed, retryAt, err := rl.Reserve(ctx, f.key, ids, f.limit, f.window)
+	if err != nil {
+		logger.Error("rate_limit: reserve failed, passing batch through", "processor", f.name, "key", f.key, "error", err)
+		return items, nil
+	}
+
+	for _, item := range items[:allowed] {
+		item.AddRateLimit(f.key)
+	}
+
+	if deferred := items[allowed:]; len(deferred) > 0 {
+		logger.Info("rate_limit: deferred items", "processor", f.name, "key", f.key, "limit", f.limit, "window", f.window, "deferred", len(deferred), "retry_at", retryAt)
+		for _, item := range deferred {
+			f.postpone(ctx, state, item, retryAt)
+		}
+	}
+	return items[:allowed], nil
+}
+
+// Admit reserves a slot for a single item, as the outbox does before each
+// delivery. When there is none it returns when the next one frees up.
+func (f *RateLimitFilter) Admit(ctx context.Context, state types.StateAccessor, item *types.Item) (bool, time.Time) {
+	rl := state.GetRateLimiter()
+	if rl == nil || f.limit <= 0 {
+		return true, time.Time{}
+	}
+
+	allowed, retryAt, err := rl.Reserve(ctx, f.key, []string{item.ID}, f.limit, f.window)
+	if err != nil {
+		state.GetLogger().Error("rate_limit: reserve failed, passing item through", "processor", f.name, "key", f.key, "item_id", item.ID, "error", err)
+		return true, time.Time{}
+	}
+	if allowed == 0 {
+		return false, retryAt
+	}
+	item.AddRateLimit(f.key)
+	return true, time.Time{}
+}
+
+// postpone hands item to the outbox, giving back any slot an earlier rate
+// limit in the chain took for it.
+func (f *RateLimitFilter) postpone(ctx context.Context, state types.StateAccessor, item *types.Item, retryAt time.Time) {
+	logger := state.GetLogger()
+	ReleaseRateLimits(ctx, state, item)
+
+	payload, err := json.Marshal(item.Clone())
+	if err != nil {
+		logger.Error("rate_limit: failed to encode deferred item", "processor", f.name, "item_id", item.ID, "error", err)
+		return
+	}
+	if err := state.GetStorage().Outbox().Defer(ctx, item.ID, f.target, payload, "deferred by "+f.name, retryAt); err != nil {
+		logger.Error("rate_limit: failed to defer item", "processor", f.name, "item_id", item.ID, "error", err)
+	}
+}
+
+// ReleaseRateLimits gives back every slot rate limits reserved for item.
+func ReleaseRateLimits(ctx context.Context, state types.StateAccessor, item *types.Item) {
+	rl := state.GetRateLimiter()
+	if rl == nil {
+		return
+	}
+	for _, key := range item.TakeRateLimits() {
+		if err := rl.Release(ctx, key, item.ID); err != nil {
+			state.GetLogger().Error("rate_limit: failed to release slot", "key", key, "item_id", item.ID, "error", err)
+		}
+	}
+}
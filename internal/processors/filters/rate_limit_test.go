@@ -0,0 +1,77 @@
+package filters
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"cartero/internal/types"
+)
+
+// countingLimiter admits limit ids per key and tracks which hold a slot.
+type countingLimiter struct {
+	slots map[string]map[string]bool
+}
+
+func (l *countingLimiter) Reserve(_ context.Context, key string, ids []string, limit int, window time.Duration) (int, time.Time, error) {
+	if l.slots[key] == nil {
+		l.slots[key] = make(map[string]bool)
+	}
+	allowed := min(max(limit-len(l.slots[key]), 0), len(ids))
+	for _, id := range ids[:allowed] {
+		l.slots[key][id] = true
+	}
+	return allowed, time.Now().Add(window), nil
+}
+
+func (l *countingLimiter) Release(_ context.Context, key string, ids ...string) error {
+	for _, id := range ids {
+		delete(l.slots[key], id)
+	}
+	return nil
+}
+
+type rateLimitState struct {
+	testState
+	limiter *countingLimiter
+}
+
+func (s rateLimitState) GetRateLimiter() types.RateLimiter { return s.limiter }
+
+func TestRateLimitSlotsStayOutOfMetadata(t *testing.T) {
+	ctx := context.Background()
+	limiter := &countingLimiter{slots: make(map[string]map[string]bool)}
+	state := rateLimitState{limiter: limiter}
+	f := NewRateLimitFilter("per_minute", "mastodon", 5, time.Minute)
+
+	item := &types.Item{ID: "a", Metadata: map[string]any{"author": "gopher"}}
+	out, err := f.Process(ctx, state, []*types.Item{item})
+	if err != nil || len(out) != 1 {
+		t.Fatalf("Process = %d items, %v", len(out), err)
+	}
+	if !limiter.slots["mastodon:per_minute"]["a"] {
+		t.Fatal("no slot reserved for the item")
+	}
+
+	// Neither storage nor the outbox payload may carry the reservation.
+	if len(item.GetMetadata()) != 1 {
+		t.Errorf("metadata = %v, want only the source's fields", item.GetMetadata())
+	}
+	payload, err := json.Marshal(item.Clone())
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	var decoded types.Item
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if keys := decoded.TakeRateLimits(); len(keys) != 0 {
+		t.Errorf("decoded item holds slots %v", keys)
+	}
+
+	ReleaseRateLimits(ctx, state, item)
+	if len(limiter.slots["mastodon:per_minute"]) != 0 {
+		t.Errorf("slots still held after release: %v", limiter.slots)
+	}
+}
@@ -0,0 +1,135 @@
+package filters
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"cartero/internal/config"
+	"cartero/internal/platforms"
+	"cartero/internal/types"
+	"cartero/internal/utils/keywords"
+	strutils "cartero/internal/utils/string"
+)
+
+const (
+	rerankKey          = "_rerank"
+	defaultRerankTopK  = 50
+	defaultRerankBlend = 0.5
+	rerankDocBytes     = 2000
+)
+
+type RerankFilter struct {
+	reranker platforms.Reranker
+	contexts map[string]string
+	query    string
+	topK     int
+	weight   float64
+}
+
+func NewRerankFilter(reranker platforms.Reranker, kws []keywords.KeywordWithContext, settings config.RerankSettings) *RerankFilter {
+	topK := settings.TopK
+	if topK <= 0 {
+		topK = defaultRerankTopK
+	}
+
+	weight := settings.Weight
+	if weight <= 0 || weight > 1 {
+		weight = defaultRerankBlend
+	}
+
+	contexts := make(map[string]string, len(kws))
+	for _, kw := range kws {
+		label := kw.Keyword
+		if label == "" {
+			label = kw.Context
+		}
+		text := kw.Context
+		if text == "" {
+			text = kw.Keyword
+		}
+		contexts[label] = text
+	}
+
+	return &RerankFilter{
+		reranker: reranker,
+		contexts: contexts,
+		query:    settings.Query,
+		topK:     topK,
+		weight:   weight,
+	}
+}
+
+func (f *RerankFilter) Name() string        { return filterRerank }
+func (f *RerankFilter) DependsOn() []string { return []string{filterRank} }
+
+func (f *RerankFilter) Process(ctx context.Context, state types.StateAccessor, items []*types.Item) ([]*types.Item, error) {
+	if f.reranker == nil || len(items) == 0 {
+		return items, nil
+	}
+
+	logger := state.GetLogger()
+	candidates := items[:min(f.topK, len(items))]
+
+	groups := make(map[string][]*types.Item)
+	for _, item := range candidates {
+		q := f.queryFor(item)
+		if q == "" {
+			continue
+		}
+		groups[q] = append(groups[q], item)
+	}
+
+	for q, group := range groups {
+		docs := make([]string, len(group))
+		for i, item := range group {
+			docs[i] = rerankDocument(item)
+		}
+
+		scores, err := f.reranker.Rerank(ctx, q, docs)
+		if err != nil {
+			logger.Warn("rerank: request failed, keeping rank scores", "query", q, "items", len(group), "error", err)
+			continue
+		}
+		if len(scores) != len(group) {
+			logger.Warn("rerank: score count mismatch, keeping rank scores", "query", q, "items", len(group), "scores", len(scores))
+			continue
+		}
+
+		for i, item := range group {
+			blended := (1-f.weight)*item.GetScore() + f.weight*scores[i]
+			item.AddMetadata(rerankKey, scores[i])
+			item.SetScore(blended)
+			logger.Debug("rerank: scored", "score", blended, "rerank", scores[i], "title", item.GetTitle())
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].GetScore() > candidates[j].GetScore() })
+	return items, nil
+}
+
+func (f *RerankFilter) queryFor(item *types.Item) string {
+	if f.query != "" {
+		return f.query
+	}
+	interest := item.GetMatchedKeywords()
+	if text, ok := f.contexts[interest]; ok {
+		return text
+	}
+	return interest
+}
+
+func rerankDocument(item *types.Item) string {
+	parts := []string{item.GetTitle()}
+	if article := item.GetArticle(); article != nil {
+		if article.Description != "" {
+			parts = append(parts, article.Description)
+		}
+		if article.Text != "" {
+			parts = append(parts, article.Text)
+		}
+	} else if desc := item.GetDescription(); desc != "" {
+		parts = append(parts, desc)
+	}
+	return strutils.Truncate(strings.Join(parts, "\n\n"), rerankDocBytes)
+}
@@ -0,0 +1,143 @@
+package filters
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"cartero/internal/config"
+	"cartero/internal/platforms"
+	"cartero/internal/types"
+	"cartero/internal/utils/keywords"
+)
+
+type testState struct {
+	types.StateAccessor
+}
+
+func (testState) GetLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+type rerankRequest struct {
+	Query string   `json:"query"`
+	Texts []string `json:"texts"`
+}
+
+// newRerankServer stands in for a TEI /rerank endpoint. Documents that
+// contain want score 1, the rest 0, and results come back best first like
+// TEI returns them.
+func newRerankServer(t *testing.T, want string, queries *[]string) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rerank" {
+			http.NotFound(w, r)
+			return
+		}
+		var req rerankRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		*queries = append(*queries, req.Query)
+		mu.Unlock()
+
+		var hits, misses []map[string]any
+		for i, text := range req.Texts {
+			if strings.Contains(text, want) {
+				hits = append(hits, map[string]any{"index": i, "score": 1.0})
+			} else {
+				misses = append(misses, map[string]any{"index": i, "score": 0.0})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(append(hits, misses...))
+	}))
+}
+
+func rankedItems() []*types.Item {
+	titles := []string{"Go generics", "Rust borrow checker", "Python typing"}
+	scores := []float64{0.9, 0.8, 0.7}
+
+	items := make([]*types.Item, len(titles))
+	for i, title := range titles {
+		items[i] = &types.Item{ID: title, Title: title, MatchedKeywords: "languages"}
+		items[i].SetScore(scores[i])
+	}
+	return items
+}
+
+func TestRerankBlendsScoresAndReorders(t *testing.T) {
+	var queries []string
+	srv := newRerankServer(t, "Rust", &queries)
+	defer srv.Close()
+
+	kws := []keywords.KeywordWithContext{{Keyword: "languages", Context: "programming language design"}}
+	f := NewRerankFilter(platforms.NewTEIReranker(srv.URL), kws, config.RerankSettings{Weight: 0.5})
+
+	out, err := f.Process(context.Background(), testState{}, rankedItems())
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+
+	if len(queries) != 1 || queries[0] != "programming language design" {
+		t.Fatalf("queries = %q, want the interest's context once", queries)
+	}
+	if out[0].ID != "Rust borrow checker" {
+		t.Fatalf("first item = %q, want the reranked one", out[0].ID)
+	}
+	if got, want := out[0].GetScore(), 0.5*0.8+0.5*1.0; got != want {
+		t.Errorf("blended score = %v, want %v", got, want)
+	}
+	if got, want := out[1].GetScore(), 0.5*0.9; got != want {
+		t.Errorf("second score = %v, want %v", got, want)
+	}
+}
+
+func TestRerankOnlyTopK(t *testing.T) {
+	var queries []string
+	srv := newRerankServer(t, "Python", &queries)
+	defer srv.Close()
+
+	f := NewRerankFilter(platforms.NewTEIReranker(srv.URL), nil, config.RerankSettings{TopK: 2, Query: "typing"})
+
+	out, err := f.Process(context.Background(), testState{}, rankedItems())
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+
+	if out[2].ID != "Python typing" || out[2].GetScore() != 0.7 {
+		t.Errorf("item past top_k = %q with score %v, want it untouched", out[2].ID, out[2].GetScore())
+	}
+	if queries[0] != "typing" {
+		t.Errorf("query = %q, want the configured one", queries[0])
+	}
+}
+
+func TestRerankKeepsRankScoresOnError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model loading", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	f := NewRerankFilter(platforms.NewTEIReranker(srv.URL), nil, config.RerankSettings{Query: "anything"})
+
+	out, err := f.Process(context.Background(), testState{}, rankedItems())
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+
+	want := []float64{0.9, 0.8, 0.7}
+	for i, item := range out {
+		if item.GetScore() != want[i] {
+			t.Errorf("item %d score = %v, want %v", i, item.GetScore(), want[i])
+		}
+	}
+}
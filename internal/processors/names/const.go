@@ -11,4 +11,13 @@ const (
 	TemplateTransformer = "template"
 	EmbedText           = "embed_text"
 	EmbedDedupe         = "embed_dedupe"
+	Rerank              = "rerank"
+	RateLimit           = "rate_limit"
+	Limit               = "limit"
+
+	// Processors every chain has without being configured.
+	PublishedDedupe = "published_dedupe"
+	Rank            = "rank"
+	Diversify       = "diversify"
+	MinScore        = "min_score"
 )
@@ -0,0 +1,70 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DigestQueue holds items accepted by batching targets until their batch is
+// sent. Each target has its own hash of item ID to encoded item, and a key
+// with the time its last batch went out.
+type DigestQueue struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewDigestQueue(client *redis.Client, prefix string) *DigestQueue {
+	return &DigestQueue{client: client, prefix: prefix}
+}
+
+func (q *DigestQueue) key(name string) string {
+	return q.prefix + ":digest:" + name
+}
+
+func (q *DigestQueue) Add(ctx context.Context, name, id string, payload []byte) error {
+	return q.client.HSet(ctx, q.key(name), id, payload).Err()
+}
+
+func (q *DigestQueue) Has(ctx context.Context, name, id string) (bool, error) {
+	return q.client.HExists(ctx, q.key(name), id).Result()
+}
+
+func (q *DigestQueue) All(ctx context.Context, name string) (map[string][]byte, error) {
+	values, err := q.client.HGetAll(ctx, q.key(name)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string][]byte, len(values))
+	for id, payload := range values {
+		out[id] = []byte(payload)
+	}
+	return out, nil
+}
+
+func (q *DigestQueue) Remove(ctx context.Context, name string, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return q.client.HDel(ctx, q.key(name), ids...).Err()
+}
+
+// LastSent returns when name's last batch was sent, or the zero time if
+// none was recorded.
+func (q *DigestQueue) LastSent(ctx context.Context, name string) (time.Time, error) {
+	ms, err := q.client.Get(ctx, q.key(name)+":sent").Int64()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(ms), nil
+}
+
+func (q *DigestQueue) SetLastSent(ctx context.Context, name string, at time.Time) error {
+	return q.client.Set(ctx, q.key(name)+":sent", at.UnixMilli(), 0).Err()
+}
@@ -0,0 +1,40 @@
+package queue
+
+import (
+	"context"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// LinkIndex remembers which URLs a target has already saved remotely. Each
+// target has its own hash of URL hash to the remote ID.
+type LinkIndex struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewLinkIndex(client *redis.Client, prefix string) *LinkIndex {
+	return &LinkIndex{client: client, prefix: prefix}
+}
+
+func (l *LinkIndex) key(name string) string {
+	return l.prefix + ":links:" + name
+}
+
+// Get returns the remote ID saved for hash, or "" when there is none.
+func (l *LinkIndex) Get(ctx context.Context, name, hash string) (string, error) {
+	id, err := l.client.HGet(ctx, l.key(name), hash).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return id, err
+}
+
+func (l *LinkIndex) Set(ctx context.Context, name, hash, id string) error {
+	return l.client.HSet(ctx, l.key(name), hash, id).Err()
+}
+
+func (l *LinkIndex) Remove(ctx context.Context, name, hash string) error {
+	return l.client.HDel(ctx, l.key(name), hash).Err()
+}
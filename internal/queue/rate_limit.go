@@ -0,0 +1,82 @@
+package queue
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// reserveScript trims the window, counts what is left and takes the free
+// slots in one step, so concurrent workers can't both see the same room.
+// It returns the number of slots taken and the score of the oldest slot.
+var reserveScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', '(' .. (now - window))
+
+local used = redis.call('ZCARD', KEYS[1])
+local allowed = math.min(math.max(limit - used, 0), #ARGV - 3)
+for i = 1, allowed do
+	redis.call('ZADD', KEYS[1], now, ARGV[3 + i])
+end
+if allowed > 0 then
+	redis.call('PEXPIRE', KEYS[1], window)
+end
+
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if #oldest == 0 then
+	return {allowed, now}
+end
+return {allowed, tonumber(oldest[2])}
+`)
+
+type RateLimiter struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRateLimiter(client *redis.Client, prefix string) *RateLimiter {
+	return &RateLimiter{client: client, prefix: prefix}
+}
+
+func (r *RateLimiter) key(name string) string {
+	return r.prefix + ":ratelimit:" + name
+}
+
+func (r *RateLimiter) Reserve(ctx context.Context, name string, ids []string, limit int, window time.Duration) (int, time.Time, error) {
+	if len(ids) == 0 {
+		return 0, time.Time{}, nil
+	}
+
+	args := make([]any, 0, len(ids)+3)
+	args = append(args, time.Now().UnixMilli(), window.Milliseconds(), limit)
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	res, err := reserveScript.Run(ctx, r.client, []string{r.key(name)}, args...).Int64Slice()
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	allowed := int(res[0])
+	if allowed == len(ids) {
+		return allowed, time.Time{}, nil
+	}
+	return allowed, time.UnixMilli(res[1]).Add(window), nil
+}
+
+// Release gives back the slots held by ids, e.g. after a failed delivery.
+func (r *RateLimiter) Release(ctx context.Context, name string, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	members := make([]any, len(ids))
+	for i, id := range ids {
+		members[i] = id
+	}
+	return r.client.ZRem(ctx, r.key(name), members...).Err()
+}
@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cartero/internal/storage"
+)
+
+const (
+	// GeneratorCollection is the collection feed generator records live in.
+	GeneratorCollection = "app.bsky.feed.generator"
+
+	feedSkeletonLimit    = 50
+	maxFeedSkeletonLimit = 100
+)
+
+// BlueskyFeed is a custom feed served through getFeedSkeleton: the posts
+// the bluesky target Target made, optionally only for entries that matched
+// one of Interests.
+type BlueskyFeed struct {
+	Name      string
+	Target    string
+	Interests []string
+	ByScore   bool
+	Window    time.Duration
+}
+
+type skeletonPost struct {
+	Post string `json:"post"`
+}
+
+type skeletonResponse struct {
+	Cursor string         `json:"cursor,omitempty"`
+	Feed   []skeletonPost `json:"feed"`
+}
+
+type describedFeed struct {
+	URI string `json:"uri"`
+}
+
+type describeResponse struct {
+	DID   string          `json:"did"`
+	Feeds []describedFeed `json:"feeds"`
+}
+
+// FeedURI is the at:// URI of the generator record for a feed.
+func FeedURI(publisherDID, name string) string {
+	return "at://" + publisherDID + "/" + GeneratorCollection + "/" + name
+}
+
+// FeedSkeleton implements app.bsky.feed.getFeedSkeleton.
+func (h *Handler) FeedSkeleton(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	feed, ok := h.blueskyFeed(query.Get("feed"))
+	if !ok {
+		writeXRPCError(w, http.StatusBadRequest, "UnknownFeed", "unknown feed")
+		return
+	}
+
+	limit := feedSkeletonLimit
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxFeedSkeletonLimit {
+			writeXRPCError(w, http.StatusBadRequest, "InvalidRequest", "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+
+	var after *storage.FeedPost
+	if v := query.Get("cursor"); v != "" {
+		post, err := parseFeedCursor(v)
+		if err != nil {
+			writeXRPCError(w, http.StatusBadRequest, "InvalidRequest", "malformed cursor")
+			return
+		}
+		after = post
+	}
+
+	q := storage.FeedPostQuery{
+		Target:    feed.Target,
+		Interests: feed.Interests,
+		ByScore:   feed.ByScore,
+		After:     after,
+		Limit:     limit,
+	}
+	if feed.Window > 0 {
+		q.Since = time.Now().Add(-feed.Window)
+	}
+
+	posts, err := h.entryStore.ListFeedPosts(r.Context(), q)
+	if err != nil {
+		writeXRPCError(w, http.StatusInternalServerError, "InternalServerError", err.Error())
+		return
+	}
+
+	resp := skeletonResponse{Feed: make([]skeletonPost, 0, len(posts))}
+	for _, post := range posts {
+		resp.Feed = append(resp.Feed, skeletonPost{Post: post.URI})
+	}
+	if len(posts) == limit {
+		resp.Cursor = feedCursor(posts[len(posts)-1])
+	}
+
+	writeJSON(w, resp)
+}
+
+// DescribeFeedGenerator implements app.bsky.feed.describeFeedGenerator.
+func (h *Handler) DescribeFeedGenerator(w http.ResponseWriter, r *http.Request) {
+	resp := describeResponse{DID: h.config.ServiceDID, Feeds: make([]describedFeed, 0, len(h.config.BlueskyFeeds))}
+	if h.config.PublisherDID != "" {
+		for _, feed := range h.config.BlueskyFeeds {
+			resp.Feeds = append(resp.Feeds, describedFeed{URI: FeedURI(h.config.PublisherDID, feed.Name)})
+		}
+	}
+	writeJSON(w, resp)
+}
+
+// DIDDocument serves the did:web document pointing Bluesky at this server.
+func (h *Handler) DIDDocument(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(h.config.ServiceDID, "did:web:") {
+		http.NotFound(w, r)
+		return
+	}
+
+	writeJSON(w, map[string]any{
+		"@context": []string{"https://www.w3.org/ns/did/v1"},
+		"id":       h.config.ServiceDID,
+		"service": []map[string]string{{
+			"id":              "#bsky_fg",
+			"type":            "BskyFeedGenerator",
+			"serviceEndpoint": strings.TrimRight(h.config.SiteURL, "/"),
+		}},
+	})
+}
+
+// blueskyFeed finds the feed an at:// generator URI names. The publisher
+// is only checked when it is known.
+func (h *Handler) blueskyFeed(uri string) (BlueskyFeed, bool) {
+	parts := strings.Split(strings.TrimPrefix(uri, "at://"), "/")
+	if len(parts) != 3 || parts[1] != GeneratorCollection {
+		return BlueskyFeed{}, false
+	}
+	if h.config.PublisherDID != "" && parts[0] != h.config.PublisherDID {
+		return BlueskyFeed{}, false
+	}
+
+	for _, feed := range h.config.BlueskyFeeds {
+		if feed.Name == parts[2] {
+			return feed, true
+		}
+	}
+	return BlueskyFeed{}, false
+}
+
+// feedCursor encodes the last post of a page as
+// <published_at µs>:<score>:<item ID>.
+func feedCursor(post storage.FeedPost) string {
+	return strconv.FormatInt(post.PublishedAt.UnixMicro(), 10) + ":" +
+		strconv.FormatFloat(post.Score, 'g', -1, 64) + ":" + post.ItemID
+}
+
+func parseFeedCursor(cursor string) (*storage.FeedPost, error) {
+	parts := strings.SplitN(cursor, ":", 3)
+	if len(parts) != 3 || parts[2] == "" {
+		return nil, strconv.ErrSyntax
+	}
+
+	micros, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	score, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &storage.FeedPost{
+		ItemID:      parts[2],
+		Score:       score,
+		PublishedAt: time.UnixMicro(micros),
+	}, nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeXRPCError(w http.ResponseWriter, status int, name, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": name, "message": message})
+}
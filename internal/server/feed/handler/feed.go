@@ -96,6 +96,25 @@ func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) buildFeed(entries []storage.FeedEntry) *feeds.Feed {
+	return BuildFeed(FeedInfo{
+		Title:       fmt.Sprintf("Cartero Feed (%s)", h.config.Name),
+		Link:        "http://localhost/",
+		Description: "Content aggregation feed from Cartero",
+		MaxItems:    h.config.MaxItems,
+	}, entries)
+}
+
+// FeedInfo describes the channel of a feed built by BuildFeed.
+type FeedInfo struct {
+	Title       string
+	Link        string
+	Description string
+	MaxItems    int
+}
+
+// BuildFeed turns entries into a feed that can be written as RSS, Atom or
+// JSON Feed. At most info.MaxItems entries are included.
+func BuildFeed(info FeedInfo, entries []storage.FeedEntry) *feeds.Feed {
 	items := make([]*feeds.Item, 0, len(entries))
 
 	for _, entry := range entries {
@@ -111,14 +130,14 @@ func (h *Handler) buildFeed(entries []storage.FeedEntry) *feeds.Feed {
 		items = append(items, item)
 	}
 
-	if len(items) > h.config.MaxItems {
-		items = items[:h.config.MaxItems]
+	if len(items) > info.MaxItems {
+		items = items[:info.MaxItems]
 	}
 
 	return &feeds.Feed{
-		Title:       fmt.Sprintf("Cartero Feed (%s)", h.config.Name),
-		Link:        &feeds.Link{Href: "http://localhost/"},
-		Description: "Content aggregation feed from Cartero",
+		Title:       info.Title,
+		Link:        &feeds.Link{Href: info.Link},
+		Description: info.Description,
 		Author:      &feeds.Author{Name: "Cartero"},
 		Created:     time.Now().UTC(),
 		Items:       items,
@@ -18,6 +18,11 @@ type Config struct {
 	SiteName          string
 	SiteDescription   string
 	SearchMaxDistance float64
+	// ServiceDID, PublisherDID and BlueskyFeeds make the server a Bluesky
+	// feed generator; see bluesky.go.
+	ServiceDID   string
+	PublisherDID string
+	BlueskyFeeds []BlueskyFeed
 }
 
 type Handler struct {
@@ -30,7 +35,7 @@ type Handler struct {
 
 func New(config Config, entryStore storage.EntryStore, embedder platforms.Embedder) *Handler {
 	tmpl := &template.Template{}
-	if err := tmpl.Load("templates/homepage.gotmpl", template.HtmlTemplate, funcMap()); err != nil {
+	if err := tmpl.Load("templates/homepage.gotmpl", template.HtmlTemplate, FuncMap()); err != nil {
 		panic(err.Error())
 	}
 
@@ -37,11 +37,14 @@ func (h *Handler) Homepage(w http.ResponseWriter, r *http.Request) {
 		"TotalPages":  result.TotalPages,
 		"HasNext":     result.HasNext,
 		"HasPrev":     result.HasPrevious,
+		"PrevURL":     fmt.Sprintf("/?page=%d", result.Page-1),
+		"NextURL":     fmt.Sprintf("/?page=%d", result.Page+1),
 		"Total":       result.Total,
 		"BaseURL":     h.config.SiteURL,
 		"Canonical":   h.config.SiteURL + r.URL.RequestURI(),
 		"Description": h.config.SiteDescription,
 		"NoIndex":     false,
+		"Static":      false,
 	}
 
 	html, err := h.renderBytes(data)
@@ -37,7 +37,9 @@ func timeAgo(t time.Time) string {
 	}
 }
 
-func funcMap() htmltemplate.FuncMap {
+// FuncMap returns the functions homepage.gotmpl uses, for other renderers of
+// the same template.
+func FuncMap() htmltemplate.FuncMap {
 	return htmltemplate.FuncMap{
 		"timeAgo": timeAgo,
 		"add":     func(a, b int) int { return a + b },
@@ -26,6 +26,12 @@ func (h *Handler) Routes() http.Handler {
 	r.Get("/robots.txt", h.Robots)
 	r.Get("/sitemap.xml", h.Sitemap)
 
+	if len(h.config.BlueskyFeeds) > 0 {
+		r.Get("/.well-known/did.json", h.DIDDocument)
+		r.Get("/xrpc/app.bsky.feed.getFeedSkeleton", h.FeedSkeleton)
+		r.Get("/xrpc/app.bsky.feed.describeFeedGenerator", h.DescribeFeedGenerator)
+	}
+
 	fileServer := http.FileServer(http.Dir("assets"))
 	r.Handle("/assets/*", http.StripPrefix("/assets/", fileServer))
 
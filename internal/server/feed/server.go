@@ -19,6 +19,9 @@ type Config struct {
 	SiteName          string
 	SiteDescription   string
 	SearchMaxDistance float64
+	ServiceDID        string
+	PublisherDID      string
+	BlueskyFeeds      []handler.BlueskyFeed
 }
 
 type Server struct {
@@ -48,6 +51,9 @@ func New(name string, config Config, entryStore storage.EntryStore, embedder pla
 		SiteName:          config.SiteName,
 		SiteDescription:   config.SiteDescription,
 		SearchMaxDistance: config.SearchMaxDistance,
+		ServiceDID:        config.ServiceDID,
+		PublisherDID:      config.PublisherDID,
+		BlueskyFeeds:      config.BlueskyFeeds,
 	}, entryStore, embedder)
 
 	return &Server{
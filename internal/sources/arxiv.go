@@ -0,0 +1,245 @@
+package sources
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"cartero/internal/config"
+	"cartero/internal/types"
+)
+
+const arxivAPIURL = "https://export.arxiv.org/api/query"
+
+// arxivVersion matches the version suffix of an arXiv ID, as in 2401.01234v2.
+var arxivVersion = regexp.MustCompile(`v\d+$`)
+
+// ArxivSource reads the newest submissions matching categories and a search
+// query from the arXiv API. IDs drop the version, so a revision dedupes
+// against the paper it revises.
+type ArxivSource struct {
+	name       string
+	httpClient *http.Client
+	maxItems   int
+	categories []string
+	query      string
+}
+
+type arxivFeed struct {
+	Entries []ArxivEntry `xml:"entry"`
+}
+
+type ArxivEntry struct {
+	ID        string `xml:"id"`
+	Title     string `xml:"title"`
+	Summary   string `xml:"summary"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+	Comment   string `xml:"http://arxiv.org/schemas/atom comment"`
+	Authors   []struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+	Links []struct {
+		Href  string `xml:"href,attr"`
+		Rel   string `xml:"rel,attr"`
+		Title string `xml:"title,attr"`
+	} `xml:"link"`
+	PrimaryCategory struct {
+		Term string `xml:"term,attr"`
+	} `xml:"http://arxiv.org/schemas/atom primary_category"`
+	Categories []struct {
+		Term string `xml:"term,attr"`
+	} `xml:"category"`
+}
+
+func NewArxivSource(name string, settings config.ArxivSettings, maxItems int) (*ArxivSource, error) {
+	if len(settings.Categories) == 0 && settings.Query == "" {
+		return nil, fmt.Errorf("arxiv: categories or query is required")
+	}
+	if maxItems == 0 {
+		maxItems = 50
+	}
+
+	return &ArxivSource{
+		name:       name,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		maxItems:   maxItems,
+		categories: settings.Categories,
+		query:      settings.Query,
+	}, nil
+}
+
+func (a *ArxivSource) Name() string {
+	return a.name
+}
+
+func (a *ArxivSource) Initialize(ctx context.Context) error {
+	return nil
+}
+
+func (a *ArxivSource) Fetch(ctx context.Context, state types.StateAccessor) ([]*types.Item, error) {
+	logger := state.GetLogger()
+
+	entries, err := a.fetchEntries(ctx)
+	if err != nil {
+		logger.Error("arXiv source error fetching entries", "source", a.name, "error", err)
+		return nil, err
+	}
+
+	logger.Debug("arXiv source retrieved entries", "source", a.name, "count", len(entries))
+
+	out := make([]*types.Item, 0, len(entries))
+	for _, entry := range entries {
+		item := a.newItem(entry)
+		if item == nil {
+			logger.Warn("arXiv source skipped entry without ID", "source", a.name, "title", entry.Title)
+			continue
+		}
+		out = append(out, item)
+	}
+
+	logger.Debug("arXiv source finished fetching all items", "source", a.name, "count", len(out))
+	return out, nil
+}
+
+func (a *ArxivSource) newItem(entry ArxivEntry) *types.Item {
+	id, version := arxivID(entry.ID)
+	if id == "" {
+		return nil
+	}
+
+	absURL := "https://arxiv.org/abs/" + id
+	pdfURL := "https://arxiv.org/pdf/" + id
+	link, _ := url.Parse(absURL)
+
+	title := collapseSpace(entry.Title)
+	abstract := collapseSpace(entry.Summary)
+	published, _ := time.Parse(time.RFC3339, entry.Published)
+
+	authors := make([]string, 0, len(entry.Authors))
+	for _, author := range entry.Authors {
+		authors = append(authors, collapseSpace(author.Name))
+	}
+	categories := make([]string, 0, len(entry.Categories))
+	for _, category := range entry.Categories {
+		categories = append(categories, category.Term)
+	}
+
+	byline := strings.Join(authors, ", ")
+	if len(authors) > 3 {
+		byline = strings.Join(authors[:3], ", ") + " et al."
+	}
+
+	return &types.Item{
+		ID:        "arxiv_" + id,
+		Title:     title,
+		URL:       link,
+		Content:   entry,
+		Source:    a.name,
+		Route:     a.name,
+		Timestamp: published,
+		// The abstract is what gets embedded and ranked; the PDF never is.
+		TextContent: &types.Article{
+			Text:        abstract,
+			Description: abstract,
+		},
+		Metadata: map[string]interface{}{
+			"title":            title,
+			"link":             absURL,
+			"author":           byline,
+			"authors":          authors,
+			"primary_category": entry.PrimaryCategory.Term,
+			"categories":       categories,
+			"category":         entry.PrimaryCategory.Term,
+			"arxiv_id":         id,
+			"version":          version,
+			"abs":              absURL,
+			"pdf":              pdfURL,
+			"comment":          collapseSpace(entry.Comment),
+			types.KeepTextKey:  true,
+		},
+	}
+}
+
+func (a *ArxivSource) fetchEntries(ctx context.Context) ([]ArxivEntry, error) {
+	params := url.Values{}
+	params.Set("search_query", a.searchQuery())
+	params.Set("sortBy", "submittedDate")
+	params.Set("sortOrder", "descending")
+	params.Set("max_results", strconv.Itoa(a.maxItems))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", arxivAPIURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", "Cartero-Bot/1.0")
+	req.Header.Set("Accept", "application/atom+xml")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch entries: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var feed arxivFeed
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		return nil, fmt.Errorf("failed to parse Atom: %w", err)
+	}
+
+	return feed.Entries, nil
+}
+
+// searchQuery ORs the categories together and ANDs them with the query.
+func (a *ArxivSource) searchQuery() string {
+	cats := make([]string, 0, len(a.categories))
+	for _, category := range a.categories {
+		cats = append(cats, "cat:"+category)
+	}
+
+	var parts []string
+	if len(cats) > 0 {
+		parts = append(parts, "("+strings.Join(cats, " OR ")+")")
+	}
+	if a.query != "" {
+		parts = append(parts, "("+a.query+")")
+	}
+	return strings.Join(parts, " AND ")
+}
+
+// arxivID turns an entry ID such as http://arxiv.org/abs/2401.01234v2 into
+// the bare ID and its version, 2401.01234 and "v2". Old style IDs like
+// hep-th/9901001v1 keep their archive prefix.
+func arxivID(entryID string) (string, string) {
+	_, id, ok := strings.Cut(entryID, "/abs/")
+	if !ok {
+		return "", ""
+	}
+	version := arxivVersion.FindString(id)
+	return strings.TrimSuffix(id, version), version
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func (a *ArxivSource) Shutdown(ctx context.Context) error {
+	a.httpClient.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,444 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cartero/internal/config"
+	"cartero/internal/types"
+	strutils "cartero/internal/utils/string"
+)
+
+const (
+	githubAPIURL = "https://api.github.com"
+	// githubMaxText caps the README or release notes kept as article text.
+	githubMaxText = 20000
+)
+
+// GitHubSource follows GitHub in one of three modes: "releases" of a list
+// of repos, "search" for repos by topic or language created within a window
+// and sorted by stars, or "starred" for repos the given users starred within
+// the window. Responses are cached with their ETag and requested again
+// conditionally, which doesn't count against the rate limit. The cache only
+// holds what the current and the previous fetch requested.
+type GitHubSource struct {
+	name       string
+	httpClient *http.Client
+	maxItems   int
+	mode       string
+	repos      []string
+	topic      string
+	language   string
+	users      []string
+	window     time.Duration
+	token      string
+
+	mu       sync.Mutex
+	cache    map[string]githubCached
+	previous map[string]githubCached
+}
+
+type githubCached struct {
+	etag string
+	body []byte
+}
+
+type GitHubRepo struct {
+	ID              int64     `json:"id"`
+	FullName        string    `json:"full_name"`
+	HTMLURL         string    `json:"html_url"`
+	Description     string    `json:"description"`
+	Homepage        string    `json:"homepage"`
+	Language        string    `json:"language"`
+	Topics          []string  `json:"topics"`
+	StargazersCount int       `json:"stargazers_count"`
+	ForksCount      int       `json:"forks_count"`
+	Fork            bool      `json:"fork"`
+	Archived        bool      `json:"archived"`
+	CreatedAt       time.Time `json:"created_at"`
+	PushedAt        time.Time `json:"pushed_at"`
+	Owner           struct {
+		Login     string `json:"login"`
+		AvatarURL string `json:"avatar_url"`
+	} `json:"owner"`
+}
+
+type GitHubRelease struct {
+	ID          int64     `json:"id"`
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	Body        string    `json:"body"`
+	HTMLURL     string    `json:"html_url"`
+	Draft       bool      `json:"draft"`
+	Prerelease  bool      `json:"prerelease"`
+	PublishedAt time.Time `json:"published_at"`
+	Author      struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+type githubStar struct {
+	StarredAt time.Time  `json:"starred_at"`
+	Repo      GitHubRepo `json:"repo"`
+}
+
+func NewGitHubSource(name string, settings config.GitHubSettings, maxItems int) (*GitHubSource, error) {
+	if maxItems == 0 {
+		maxItems = 20
+	}
+
+	mode := settings.Mode
+	if mode == "" {
+		mode = "releases"
+	}
+	switch mode {
+	case "releases":
+		if len(settings.Repos) == 0 {
+			return nil, fmt.Errorf("github: releases mode needs repos")
+		}
+	case "search":
+		if settings.Topic == "" && settings.Language == "" {
+			return nil, fmt.Errorf("github: search mode needs a topic or a language")
+		}
+	case "starred":
+		if len(settings.Users) == 0 {
+			return nil, fmt.Errorf("github: starred mode needs users")
+		}
+	default:
+		return nil, fmt.Errorf("github: unknown mode %q", mode)
+	}
+
+	return &GitHubSource{
+		name:       name,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		maxItems:   maxItems,
+		mode:       mode,
+		repos:      settings.Repos,
+		topic:      settings.Topic,
+		language:   settings.Language,
+		users:      settings.Users,
+		window:     config.ParseDuration(settings.Window, 7*24*time.Hour),
+		token:      settings.Token,
+		cache:      make(map[string]githubCached),
+	}, nil
+}
+
+func (g *GitHubSource) Name() string {
+	return g.name
+}
+
+func (g *GitHubSource) Initialize(ctx context.Context) error {
+	return nil
+}
+
+func (g *GitHubSource) Fetch(ctx context.Context, state types.StateAccessor) ([]*types.Item, error) {
+	logger := state.GetLogger()
+
+	// Responses this fetch doesn't ask for again are dropped at the next.
+	g.mu.Lock()
+	g.previous, g.cache = g.cache, make(map[string]githubCached)
+	g.mu.Unlock()
+
+	var out []*types.Item
+	var err error
+	switch g.mode {
+	case "releases":
+		out, err = g.fetchReleases(ctx, state)
+	case "search":
+		out, err = g.fetchSearch(ctx, state)
+	case "starred":
+		out, err = g.fetchStarred(ctx, state)
+	}
+	if err != nil {
+		logger.Error("GitHub source error fetching items", "source", g.name, "mode", g.mode, "error", err)
+		return nil, err
+	}
+
+	logger.Debug("GitHub source finished fetching all items", "source", g.name, "mode", g.mode, "count", len(out))
+	return out, nil
+}
+
+func (g *GitHubSource) fetchReleases(ctx context.Context, state types.StateAccessor) ([]*types.Item, error) {
+	logger := state.GetLogger()
+	since := time.Now().Add(-g.window)
+
+	var out []*types.Item
+	for _, name := range g.repos {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		name = strings.Trim(strings.TrimPrefix(name, "https://github.com/"), "/")
+
+		var repo GitHubRepo
+		if err := g.get(ctx, "/repos/"+name, "", &repo); err != nil {
+			logger.Warn("GitHub source error fetching repo", "source", g.name, "repo", name, "error", err)
+			continue
+		}
+
+		var releases []GitHubRelease
+		if err := g.get(ctx, "/repos/"+name+"/releases?per_page=10", "", &releases); err != nil {
+			logger.Warn("GitHub source error fetching releases", "source", g.name, "repo", name, "error", err)
+			continue
+		}
+
+		for _, release := range releases {
+			if release.Draft || release.PublishedAt.Before(since) {
+				continue
+			}
+			out = append(out, g.releaseItem(repo, release))
+			if len(out) == g.maxItems {
+				return out, nil
+			}
+		}
+	}
+	return out, nil
+}
+
+func (g *GitHubSource) releaseItem(repo GitHubRepo, release GitHubRelease) *types.Item {
+	title := release.Name
+	if title == "" {
+		title = release.TagName
+	}
+	if !strings.Contains(title, repo.FullName) {
+		title = repo.FullName + " " + title
+	}
+	releaseURL, _ := url.Parse(release.HTMLURL)
+
+	item := &types.Item{
+		ID:        fmt.Sprintf("github_release_%d", release.ID),
+		Title:     title,
+		URL:       releaseURL,
+		Content:   release,
+		Source:    g.name,
+		Route:     g.name,
+		Timestamp: release.PublishedAt,
+		Metadata:  repoMetadata(repo),
+	}
+	item.Metadata["title"] = title
+	item.Metadata["link"] = release.HTMLURL
+	item.Metadata["author"] = release.Author.Login
+	item.Metadata["tag"] = release.TagName
+	item.Metadata["prerelease"] = release.Prerelease
+
+	if body := strings.TrimSpace(release.Body); body != "" {
+		item.TextContent = &types.Article{Text: strutils.Truncate(body, githubMaxText), Description: repo.Description}
+		item.Metadata[types.KeepTextKey] = true
+	}
+	return item
+}
+
+func (g *GitHubSource) fetchSearch(ctx context.Context, state types.StateAccessor) ([]*types.Item, error) {
+	query := []string{"created:>=" + time.Now().Add(-g.window).UTC().Format("2006-01-02")}
+	if g.topic != "" {
+		query = append(query, "topic:"+g.topic)
+	}
+	if g.language != "" {
+		query = append(query, "language:"+g.language)
+	}
+
+	params := url.Values{}
+	params.Set("q", strings.Join(query, " "))
+	params.Set("sort", "stars")
+	params.Set("order", "desc")
+	params.Set("per_page", strconv.Itoa(min(g.maxItems, 100)))
+
+	var result struct {
+		Items []GitHubRepo `json:"items"`
+	}
+	if err := g.get(ctx, "/search/repositories?"+params.Encode(), "", &result); err != nil {
+		return nil, err
+	}
+
+	out := make([]*types.Item, 0, len(result.Items))
+	for _, repo := range result.Items {
+		if len(out) == g.maxItems {
+			break
+		}
+		out = append(out, g.repoItem(ctx, state, repo, repo.CreatedAt))
+	}
+	return out, nil
+}
+
+func (g *GitHubSource) fetchStarred(ctx context.Context, state types.StateAccessor) ([]*types.Item, error) {
+	logger := state.GetLogger()
+	since := time.Now().Add(-g.window)
+
+	var out []*types.Item
+	seen := make(map[int64]*types.Item)
+	for _, user := range g.users {
+		var stars []githubStar
+		// The star media type adds starred_at to each repo.
+		err := g.get(ctx, "/users/"+user+"/starred?per_page=30&sort=created", "application/vnd.github.star+json", &stars)
+		if err != nil {
+			logger.Warn("GitHub source error fetching stars", "source", g.name, "user", user, "error", err)
+			continue
+		}
+
+		for _, star := range stars {
+			if star.StarredAt.Before(since) {
+				break
+			}
+			if item, ok := seen[star.Repo.ID]; ok {
+				starredBy, _ := item.Metadata["starred_by"].([]string)
+				item.Metadata["starred_by"] = append(starredBy, user)
+				continue
+			}
+			if len(out) == g.maxItems {
+				continue
+			}
+
+			item := g.repoItem(ctx, state, star.Repo, star.StarredAt)
+			item.Metadata["starred_by"] = []string{user}
+			seen[star.Repo.ID] = item
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+// repoItem turns a repo into an item with its README as article text.
+func (g *GitHubSource) repoItem(ctx context.Context, state types.StateAccessor, repo GitHubRepo, at time.Time) *types.Item {
+	repoURL, _ := url.Parse(repo.HTMLURL)
+
+	item := &types.Item{
+		ID:        fmt.Sprintf("github_repo_%d", repo.ID),
+		Title:     repo.FullName,
+		URL:       repoURL,
+		Content:   repo,
+		Source:    g.name,
+		Route:     g.name,
+		Timestamp: at,
+		Metadata:  repoMetadata(repo),
+	}
+	if repo.Description != "" {
+		item.Title = repo.FullName + ": " + repo.Description
+	}
+	item.Metadata["title"] = item.Title
+	item.Metadata["link"] = repo.HTMLURL
+	item.Metadata["author"] = repo.Owner.Login
+	item.Metadata["score"] = repo.StargazersCount
+
+	readme, err := g.raw(ctx, "/repos/"+repo.FullName+"/readme")
+	if err != nil {
+		state.GetLogger().Debug("GitHub source has no README", "source", g.name, "repo", repo.FullName, "error", err)
+	}
+	if readme = strings.TrimSpace(readme); readme != "" {
+		item.TextContent = &types.Article{
+			Text:        strutils.Truncate(readme, githubMaxText),
+			Description: repo.Description,
+			Image:       repo.Owner.AvatarURL,
+		}
+		item.Metadata[types.KeepTextKey] = true
+	}
+	return item
+}
+
+func repoMetadata(repo GitHubRepo) map[string]interface{} {
+	return map[string]interface{}{
+		"repo":     repo.FullName,
+		"stars":    repo.StargazersCount,
+		"forks":    repo.ForksCount,
+		"language": repo.Language,
+		"topics":   repo.Topics,
+		"category": repo.Language,
+	}
+}
+
+// get decodes the JSON at path, relative to the API root.
+func (g *GitHubSource) get(ctx context.Context, path, accept string, v any) error {
+	if accept == "" {
+		accept = "application/vnd.github+json"
+	}
+	body, err := g.request(ctx, path, accept)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return nil
+}
+
+// raw returns a file's contents as they are.
+func (g *GitHubSource) raw(ctx context.Context, path string) (string, error) {
+	body, err := g.request(ctx, path, "application/vnd.github.raw")
+	return string(body), err
+}
+
+// request performs a GET, sending the ETag of the cached response so an
+// unchanged one comes back as 304 and is served from the cache.
+func (g *GitHubSource) request(ctx context.Context, path, accept string) ([]byte, error) {
+	key := accept + " " + path
+
+	g.mu.Lock()
+	cached, hasCached := g.cache[key]
+	if !hasCached {
+		cached, hasCached = g.previous[key]
+	}
+	g.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", githubAPIURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", accept)
+	req.Header.Set("User-Agent", "Cartero-Bot/1.0")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if g.token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.token)
+	}
+	if hasCached {
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && hasCached:
+		g.mu.Lock()
+		g.cache[key] = cached
+		g.mu.Unlock()
+		return cached.body, nil
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			reset, _ := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+			return nil, fmt.Errorf("rate limited until %s", time.Unix(reset, 0).Format(time.RFC3339))
+		}
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		g.mu.Lock()
+		g.cache[key] = githubCached{etag: etag, body: body}
+		g.mu.Unlock()
+	}
+	return body, nil
+}
+
+func (g *GitHubSource) Shutdown(ctx context.Context) error {
+	g.httpClient.CloseIdleConnections()
+	return nil
+}
@@ -30,6 +30,8 @@ type HNStory struct {
 	Descendants int    `json:"descendants"`
 	Type        string `json:"type"`
 	Text        string `json:"text"`
+	Deleted     bool   `json:"deleted"`
+	Dead        bool   `json:"dead"`
 }
 
 func NewHackerNewsSource(name string, storyType string, maxItems int) *HackerNewsSource {
@@ -119,6 +121,41 @@ func (h *HackerNewsSource) Fetch(ctx context.Context, state types.StateAccessor)
 	return out, nil
 }
 
+// Recheck refetches each story and refreshes its title, score and comment
+// count. Stories that were deleted or killed (flagged) count as removed.
+func (h *HackerNewsSource) Recheck(ctx context.Context, items []*types.Item) (changed, removed []string, err error) {
+	for _, item := range items {
+		var id int64
+		if _, err := fmt.Sscanf(item.ID, "hn_%d", &id); err != nil {
+			continue
+		}
+
+		story, err := h.fetchStory(ctx, id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, nil, ctx.Err()
+			}
+			continue
+		}
+
+		if story.Deleted || story.Dead {
+			removed = append(removed, item.ID)
+			continue
+		}
+
+		item.AddMetadata("score", story.Score)
+		item.AddMetadata("comment_count", story.Descendants)
+		item.AddMetadata("comments", fmt.Sprintf("https://news.ycombinator.com/item?id=%d", story.ID))
+		if story.Title != "" && story.Title != item.GetTitle() {
+			item.SetTitle(story.Title)
+			item.AddMetadata("title", story.Title)
+			changed = append(changed, item.ID)
+		}
+	}
+
+	return changed, removed, nil
+}
+
 func (h *HackerNewsSource) fetchStoryIDs(ctx context.Context) ([]int64, error) {
 	url := fmt.Sprintf("%s/%s.json", h.apiURL, h.storyType)
 
@@ -0,0 +1,124 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"cartero/internal/archive"
+	"cartero/internal/types"
+)
+
+// JSONLSource replays archives written by the jsonl target. Each fetch
+// returns up to maxItems records, continuing where the previous fetch
+// stopped; files added or appended to later are picked up too. Replayed
+// items keep their original ID and source, so processors that drop known
+// items (dedupe, published filters) need a separate database to replay into.
+type JSONLSource struct {
+	name     string
+	path     string
+	maxItems int
+
+	// read counts the lines consumed from each file.
+	read map[string]int
+}
+
+func NewJSONLSource(name, path string, maxItems int) (*JSONLSource, error) {
+	if path == "" {
+		return nil, fmt.Errorf("jsonl source: path is required")
+	}
+	if maxItems == 0 {
+		maxItems = 100
+	}
+
+	return &JSONLSource{
+		name:     name,
+		path:     path,
+		maxItems: maxItems,
+		read:     make(map[string]int),
+	}, nil
+}
+
+func (j *JSONLSource) Name() string {
+	return j.name
+}
+
+func (j *JSONLSource) Initialize(ctx context.Context) error {
+	files, err := archive.Files(j.path)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("jsonl source: no archives match %s", j.path)
+	}
+	return nil
+}
+
+func (j *JSONLSource) Fetch(ctx context.Context, state types.StateAccessor) ([]*types.Item, error) {
+	logger := state.GetLogger()
+
+	files, err := archive.Files(j.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*types.Item
+	for _, path := range files {
+		if len(out) >= j.maxItems {
+			break
+		}
+
+		items, err := j.readFile(ctx, path, j.maxItems-len(out))
+		out = append(out, items...)
+		if err != nil {
+			logger.Error("JSONL source error reading archive", "source", j.name, "file", path, "error", err)
+			if len(out) == 0 {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	logger.Debug("JSONL source replayed items", "source", j.name, "count", len(out))
+	return out, nil
+}
+
+// readFile returns up to limit records from path after the lines already
+// consumed, and advances the file's cursor past them.
+func (j *JSONLSource) readFile(ctx context.Context, path string, limit int) ([]*types.Item, error) {
+	r, err := archive.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+
+	skip := j.read[path]
+	var out []*types.Item
+	for len(out) < limit {
+		select {
+		case <-ctx.Done():
+			return out, ctx.Err()
+		default:
+		}
+
+		rec, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return out, err
+		}
+		if r.Line() <= skip {
+			continue
+		}
+
+		j.read[path] = r.Line()
+		out = append(out, rec.Item(j.name))
+	}
+	return out, nil
+}
+
+func (j *JSONLSource) Shutdown(ctx context.Context) error {
+	return nil
+}
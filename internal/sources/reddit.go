@@ -0,0 +1,356 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"cartero/internal/config"
+	"cartero/internal/types"
+)
+
+const (
+	redditPublicURL = "https://www.reddit.com"
+	redditOAuthURL  = "https://oauth.reddit.com"
+	redditTokenURL  = "https://www.reddit.com/api/v1/access_token"
+	// Reddit throttles generic user agents hard.
+	redditUserAgent = "linux:cartero:1.0"
+)
+
+// RedditSource reads a listing of one or more subreddits, from the public
+// .json endpoints or, with app credentials, from the OAuth API which has a
+// higher rate limit.
+type RedditSource struct {
+	name         string
+	httpClient   *http.Client
+	maxItems     int
+	subreddits   []string
+	listing      string
+	period       string
+	minScore     int
+	includeFlair []string
+	excludeFlair []string
+	includeSelf  bool
+	clientID     string
+	clientSecret string
+
+	mu          sync.Mutex
+	accessToken string
+	tokenExpiry time.Time
+}
+
+type RedditPost struct {
+	ID                  string       `json:"id"`
+	Name                string       `json:"name"`
+	Title               string       `json:"title"`
+	URL                 string       `json:"url"`
+	Permalink           string       `json:"permalink"`
+	Subreddit           string       `json:"subreddit"`
+	Author              string       `json:"author"`
+	Score               int          `json:"score"`
+	NumComments         int          `json:"num_comments"`
+	CreatedUTC          float64      `json:"created_utc"`
+	IsSelf              bool         `json:"is_self"`
+	Selftext            string       `json:"selftext"`
+	LinkFlairText       string       `json:"link_flair_text"`
+	Over18              bool         `json:"over_18"`
+	Stickied            bool         `json:"stickied"`
+	Domain              string       `json:"domain"`
+	CrosspostParentList []RedditPost `json:"crosspost_parent_list"`
+}
+
+type redditListing struct {
+	Data struct {
+		Children []struct {
+			Kind string     `json:"kind"`
+			Data RedditPost `json:"data"`
+		} `json:"children"`
+	} `json:"data"`
+}
+
+func NewRedditSource(name string, settings config.RedditSettings, maxItems int) (*RedditSource, error) {
+	if len(settings.Subreddits) == 0 {
+		return nil, fmt.Errorf("reddit: subreddits is required")
+	}
+	if maxItems == 0 {
+		maxItems = 25
+	}
+
+	listing := settings.Listing
+	if listing == "" {
+		listing = "hot"
+	}
+	switch listing {
+	case "hot", "new", "top", "rising":
+	default:
+		return nil, fmt.Errorf("reddit: unknown listing %q", listing)
+	}
+
+	subreddits := make([]string, 0, len(settings.Subreddits))
+	for _, sub := range settings.Subreddits {
+		sub = strings.TrimPrefix(strings.TrimSpace(sub), "r/")
+		if sub != "" {
+			subreddits = append(subreddits, sub)
+		}
+	}
+
+	return &RedditSource{
+		name:         name,
+		httpClient:   &http.Client{Timeout: 30 * time.Second},
+		maxItems:     maxItems,
+		subreddits:   subreddits,
+		listing:      listing,
+		period:       settings.Period,
+		minScore:     settings.MinScore,
+		includeFlair: settings.IncludeFlairs,
+		excludeFlair: settings.ExcludeFlairs,
+		includeSelf:  settings.IncludeSelf,
+		clientID:     settings.ClientID,
+		clientSecret: settings.ClientSecret,
+	}, nil
+}
+
+func (r *RedditSource) Name() string {
+	return r.name
+}
+
+func (r *RedditSource) Initialize(ctx context.Context) error {
+	return nil
+}
+
+func (r *RedditSource) Fetch(ctx context.Context, state types.StateAccessor) ([]*types.Item, error) {
+	logger := state.GetLogger()
+	var out []*types.Item
+
+	posts, err := r.fetchPosts(ctx)
+	if err != nil {
+		logger.Error("Reddit source error fetching posts", "source", r.name, "error", err)
+		return nil, err
+	}
+
+	logger.Debug("Reddit source retrieved posts", "source", r.name, "count", len(posts))
+
+	for _, post := range posts {
+		if len(out) == r.maxItems {
+			break
+		}
+		if !r.shouldIncludePost(post) {
+			continue
+		}
+
+		item := r.newItem(post)
+		if item == nil {
+			continue
+		}
+		out = append(out, item)
+		logger.Debug("Reddit source published item", "source", r.name, "post_id", post.ID, "subreddit", post.Subreddit, "score", post.Score)
+	}
+
+	logger.Debug("Reddit source finished fetching all items", "source", r.name, "count", len(out))
+	return out, nil
+}
+
+// newItem maps a post to an item. A crosspost links to what the original
+// post links to, while score and comments stay those of the crosspost.
+func (r *RedditSource) newItem(post RedditPost) *types.Item {
+	target := post
+	if len(post.CrosspostParentList) > 0 {
+		target = post.CrosspostParentList[0]
+	}
+
+	comments := redditPublicURL + post.Permalink
+	link := target.URL
+	if target.IsSelf || link == "" || strings.HasPrefix(link, "/r/") {
+		link = redditPublicURL + target.Permalink
+	}
+	postURL, err := url.Parse(link)
+	if err != nil {
+		return nil
+	}
+
+	item := &types.Item{
+		ID:        fmt.Sprintf("reddit_%s", post.ID),
+		Title:     post.Title,
+		URL:       postURL,
+		Content:   post,
+		Source:    r.name,
+		Route:     r.name,
+		Timestamp: time.Unix(int64(post.CreatedUTC), 0),
+		Metadata: map[string]interface{}{
+			"title":         post.Title,
+			"link":          link,
+			"score":         post.Score,
+			"author":        post.Author,
+			"comments":      comments,
+			"comment_count": post.NumComments,
+			"subreddit":     post.Subreddit,
+			"flair":         post.LinkFlairText,
+			"category":      post.Subreddit,
+			"reddit_id":     post.ID,
+		},
+	}
+	if target.ID != post.ID {
+		item.Metadata["crosspost_of"] = redditPublicURL + target.Permalink
+	}
+
+	// The text of a self-post is right here, no need to extract it.
+	if target.IsSelf && strings.TrimSpace(target.Selftext) != "" {
+		item.TextContent = &types.Article{Text: target.Selftext}
+	}
+
+	return item
+}
+
+func (r *RedditSource) shouldIncludePost(post RedditPost) bool {
+	if post.Stickied || post.Score < r.minScore {
+		return false
+	}
+
+	target := post
+	if len(post.CrosspostParentList) > 0 {
+		target = post.CrosspostParentList[0]
+	}
+	if target.IsSelf && !r.includeSelf {
+		return false
+	}
+
+	for _, flair := range r.excludeFlair {
+		if strings.EqualFold(post.LinkFlairText, flair) {
+			return false
+		}
+	}
+	if len(r.includeFlair) == 0 {
+		return true
+	}
+	for _, flair := range r.includeFlair {
+		if strings.EqualFold(post.LinkFlairText, flair) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RedditSource) fetchPosts(ctx context.Context) ([]RedditPost, error) {
+	baseURL := redditPublicURL
+	path := "/r/" + strings.Join(r.subreddits, "+") + "/" + r.listing + ".json"
+
+	token, err := r.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		baseURL = redditOAuthURL
+		path = strings.TrimSuffix(path, ".json")
+	}
+
+	params := url.Values{}
+	params.Set("limit", fmt.Sprint(min(r.maxItems*2, 100)))
+	params.Set("raw_json", "1")
+	if r.period != "" && r.listing == "top" {
+		params.Set("t", r.period)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", redditUserAgent)
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch posts: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusUnauthorized && token != "" {
+		r.mu.Lock()
+		r.accessToken = ""
+		r.mu.Unlock()
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var listing redditListing
+	if err := json.Unmarshal(body, &listing); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	posts := make([]RedditPost, 0, len(listing.Data.Children))
+	for _, child := range listing.Data.Children {
+		if child.Kind == "t3" {
+			posts = append(posts, child.Data)
+		}
+	}
+	return posts, nil
+}
+
+// token returns an application-only OAuth token, fetching a new one when
+// it is about to expire. Without credentials it returns "".
+func (r *RedditSource) token(ctx context.Context) (string, error) {
+	if r.clientID == "" {
+		return "", nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.accessToken != "" && time.Now().Before(r.tokenExpiry) {
+		return r.accessToken, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	req, err := http.NewRequestWithContext(ctx, "POST", redditTokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.SetBasicAuth(r.clientID, r.clientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", redditUserAgent)
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch token: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected token status code: %d", resp.StatusCode)
+	}
+
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", fmt.Errorf("failed to parse token: %w", err)
+	}
+	if tok.AccessToken == "" {
+		return "", fmt.Errorf("reddit returned an empty token")
+	}
+
+	r.accessToken = tok.AccessToken
+	r.tokenExpiry = time.Now().Add(time.Duration(tok.ExpiresIn)*time.Second - time.Minute)
+	return r.accessToken, nil
+}
+
+func (r *RedditSource) Shutdown(ctx context.Context) error {
+	r.httpClient.CloseIdleConnections()
+	return nil
+}
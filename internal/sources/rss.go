@@ -153,6 +153,34 @@ func (r *RSSSource) convertToItem(feedItem *gofeed.Item) *types.Item {
 	}
 }
 
+// Recheck refetches the feed and refreshes the titles of items still in it.
+// Items that dropped out of the feed are left alone, since feeds only carry
+// their latest entries and absence doesn't mean removal.
+func (r *RSSSource) Recheck(ctx context.Context, items []*types.Item) (changed, removed []string, err error) {
+	feed, err := r.parser.ParseURLWithContext(r.feedURL, ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse feed: %w", err)
+	}
+
+	current := make(map[string]*types.Item, len(feed.Items))
+	for _, feedItem := range feed.Items {
+		item := r.convertToItem(feedItem)
+		current[item.ID] = item
+	}
+
+	for _, item := range items {
+		upstream, ok := current[item.ID]
+		if !ok || upstream.Title == "" || upstream.Title == item.GetTitle() {
+			continue
+		}
+		item.SetTitle(upstream.Title)
+		item.AddMetadata("title", upstream.Title)
+		changed = append(changed, item.ID)
+	}
+
+	return changed, nil, nil
+}
+
 func (r *RSSSource) Shutdown(ctx context.Context) error {
 	return nil
 }
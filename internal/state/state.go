@@ -27,10 +27,12 @@ type State struct {
 	Pipeline        *core.Pipeline
 	Storage         storage.StorageInterface
 	Filters         *filters.Chain
+	TargetFilters   map[string]*filters.Chain
 	Queue           *queue.Queue
 	RedisConn       *queue.RedisConnection
 	Blocklist       types.Blocklist
 	EmbedCache      types.EmbedCache
+	RateLimiter     types.RateLimiter
 	Logger          *slog.Logger
 	EmbeddedScripts embed.FS
 }
@@ -79,6 +81,8 @@ func (s *State) Initialize(ctx context.Context, configPath string) error {
 		}
 	}
 
+	s.RateLimiter = queue.NewRateLimiter(conn.Client(), s.Queue.Prefix())
+
 	s.Registry = components.NewRegistry()
 
 	storageComp := components.NewStorageComponent(s.Storage)
@@ -108,6 +112,7 @@ func (s *State) Initialize(ctx context.Context, configPath string) error {
 			SiteName:          cfg.SiteName,
 			SiteDescription:   cfg.SiteDescription,
 			SearchMaxDistance: cfg.SearchMaxDistance,
+			FeedGenerator:     cfg.FeedGenerator,
 		})
 	}
 
@@ -129,11 +134,76 @@ func (s *State) Initialize(ctx context.Context, configPath string) error {
 		return fmt.Errorf("failed to initialize pipeline: %w", err)
 	}
 
-	s.Filters = s.buildFilterChain(ctx)
+	return s.resolveChains(ctx, s.Pipeline.RouteTargetNames())
+}
+
+// LoadChains loads the config and resolves the processor chains from it
+// alone, without connecting to storage, Redis or any platform. It is enough
+// to print the chain order.
+func (s *State) LoadChains(ctx context.Context, configPath string) error {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	s.Config = cfg
+
+	// Processors only keep the platforms they are given, so the component
+	// is registered without initializing it.
+	s.Registry = components.NewRegistry()
+	if err := s.Registry.Register(s.buildPlatformComponent()); err != nil {
+		return fmt.Errorf("failed to register platform component: %w", err)
+	}
+
+	return s.resolveChains(ctx, s.configRoutes())
+}
+
+// resolveChains builds the shared processor chain and one chain per target,
+// given the enabled target names of each source.
+func (s *State) resolveChains(ctx context.Context, routes map[string][]string) error {
+	chain, err := s.buildFilterChain(ctx, routes)
+	if err != nil {
+		return fmt.Errorf("failed to build processor chain: %w", err)
+	}
+	s.Filters = chain
+	s.Logger.Info("Processor chain resolved", "order", chain.Order())
+
+	var targetNames []string
+	seen := make(map[string]bool)
+	for _, names := range routes {
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				targetNames = append(targetNames, name)
+			}
+		}
+	}
+
+	targetChains, err := s.buildTargetChains(ctx, targetNames)
+	if err != nil {
+		return fmt.Errorf("failed to build target processor chains: %w", err)
+	}
+	s.TargetFilters = targetChains
 
 	return nil
 }
 
+// configRoutes returns the enabled targets of each enabled source as
+// configured, the same routes buildPipeline creates.
+func (s *State) configRoutes() map[string][]string {
+	routes := make(map[string][]string)
+	for sourceName, sourceCfg := range s.Config.Sources {
+		if !sourceCfg.Enabled {
+			continue
+		}
+		for _, targetName := range sourceCfg.Targets {
+			if targetCfg, ok := s.Config.Targets[targetName]; ok && targetCfg.Enabled {
+				routes[sourceName] = append(routes[sourceName], targetName)
+			}
+		}
+	}
+	return routes
+}
+
 func (s *State) GetConfig() *config.Config {
 	return s.Config
 }
@@ -154,6 +224,10 @@ func (s *State) GetFilterChain() *filters.Chain {
 	return s.Filters
 }
 
+func (s *State) GetTargetFilterChains() map[string]*filters.Chain {
+	return s.TargetFilters
+}
+
 func (s *State) GetLogger() *slog.Logger {
 	return s.Logger
 }
@@ -170,12 +244,17 @@ func (s *State) GetEmbedCache() types.EmbedCache {
 	return s.EmbedCache
 }
 
+func (s *State) GetRateLimiter() types.RateLimiter {
+	return s.RateLimiter
+}
+
 func (s *State) buildPlatformComponent() *components.PlatformComponent {
 	return components.NewPlatformComponent(s.Config.Platforms)
 }
 
 func (s *State) buildPipeline(ctx context.Context) (*core.Pipeline, error) {
 	pipeline := core.NewPipeline()
+	created := make(map[string]types.Target)
 
 	for sourceName, sourceCfg := range s.Config.Sources {
 		if !sourceCfg.Enabled {
@@ -201,9 +280,13 @@ func (s *State) buildPipeline(ctx context.Context) (*core.Pipeline, error) {
 				continue
 			}
 
-			target := s.createTarget(targetName, targetCfg)
-			if target == nil {
-				return nil, fmt.Errorf("failed to create target %s for source %s", targetName, sourceName)
+			target, ok := created[targetName]
+			if !ok {
+				target = s.createTarget(targetName, targetCfg)
+				if target == nil {
+					return nil, fmt.Errorf("failed to create target %s for source %s", targetName, sourceName)
+				}
+				created[targetName] = target
 			}
 
 			routeTargets = append(routeTargets, target)
@@ -223,11 +306,25 @@ func (s *State) buildPipeline(ctx context.Context) (*core.Pipeline, error) {
 	return pipeline, nil
 }
 
-func (s *State) buildFilterChain(ctx context.Context) *filters.Chain {
+func (s *State) configureProcessor(p filters.Processor, cfg config.ProcessorConfig, skip func(string) bool) filters.Processor {
+	var deps []string
+	for _, dep := range cfg.DependsOn {
+		if procCfg, ok := s.Config.Processors[dep]; ok {
+			dep = procCfg.Type
+		}
+		if skip != nil && skip(dep) {
+			continue
+		}
+		deps = append(deps, dep)
+	}
+	return filters.Configure(p, deps, cfg.OverrideDependsOn, cfg.Order)
+}
+
+func (s *State) buildFilterChain(ctx context.Context, routes map[string][]string) (*filters.Chain, error) {
 	var fs []filters.Processor
 
 	for _, procCfg := range s.Config.Processors {
-		if !procCfg.Enabled {
+		if !procCfg.Enabled || procCfg.Type == names.RateLimit {
 			continue
 		}
 
@@ -236,18 +333,15 @@ func (s *State) buildFilterChain(ctx context.Context) *filters.Chain {
 			continue
 		}
 
-		fs = append(fs, processor)
+		fs = append(fs, s.configureProcessor(processor, procCfg, nil))
 	}
 
-	var targetNames []string
-	for name, tc := range s.Config.Targets {
-		if tc.Enabled {
-			targetNames = append(targetNames, name)
-		}
-	}
-	fs = append(fs, filters.NewPublishedDedupeFilter(targetNames))
+	extractCfg := s.Config.Processors[names.ExtractText]
+	extract := processors.NewExtractProcessor(extractCfg.Settings.ExtractTextSettings, extractCfg.Settings.Limit)
+
+	fs = append(fs, filters.NewPublishedDedupeFilter(routes))
 	fs = append(fs, filters.NewBlocklistFilter())
-	fs = append(fs, processors.NewExtractProcessor(s.Config.Processors[names.ExtractText].Settings.ExtractTextSettings))
+	fs = append(fs, s.configureProcessor(extract, extractCfg, nil))
 
 	pc := s.Registry.Get(components.PlatformComponentName).(*components.PlatformComponent)
 	fs = append(fs,
@@ -258,6 +352,95 @@ func (s *State) buildFilterChain(ctx context.Context) *filters.Chain {
 	return filters.NewChain(fs...)
 }
 
+func (s *State) buildTargetChains(ctx context.Context, targetNames []string) (map[string]*filters.Chain, error) {
+	chains := make(map[string]*filters.Chain)
+
+	var sharedRateLimits []string
+	for name, procCfg := range s.Config.Processors {
+		if procCfg.Enabled && procCfg.Type == names.RateLimit {
+			sharedRateLimits = append(sharedRateLimits, name)
+		}
+	}
+
+	for _, targetName := range targetNames {
+		tc := s.Config.Targets[targetName].Processors
+
+		var fs []filters.Processor
+		var deps []string
+
+		if tc.MinScore > 0 {
+			minScore := filters.NewMinScoreFilter(tc.MinScore)
+			fs = append(fs, minScore)
+			deps = append(deps, minScore.Name())
+		}
+
+		rateLimits := append([]string{}, sharedRateLimits...)
+
+		var extra []string
+		for _, name := range tc.Filters {
+			procCfg := s.Config.Processors[name]
+			if procCfg.Type == names.RateLimit {
+				rateLimits = append(rateLimits, name)
+				continue
+			}
+			processor := s.createProcessor(procCfg)
+			if processor == nil {
+				continue
+			}
+			processor = s.configureProcessor(processor, procCfg, s.Filters.Has)
+			fs = append(fs, filters.After(processor, deps...))
+			extra = append(extra, processor.Name())
+		}
+		deps = append(deps, extra...)
+
+		if tc.Limit > 0 {
+			limit := filters.NewLimitFilter(tc.Limit)
+			fs = append(fs, filters.After(limit, deps...))
+			deps = append(deps, limit.Name())
+		}
+
+		if tc.SummaryModel != "" {
+			settings := s.summarySettings()
+			settings.Model = tc.SummaryModel
+			summary := processors.NewSummaryProcessor(names.Summary, settings)
+			fs = append(fs, filters.After(summary, deps...))
+			deps = append(deps, summary.Name())
+		}
+
+		// Rate limits reserve slots for every item they pass and defer the
+		// rest to the outbox as they are, so they run last.
+		for _, name := range rateLimits {
+			settings := s.Config.Processors[name].Settings
+			window := config.ParseDuration(settings.Window, time.Minute)
+			rl := filters.NewRateLimitFilter(name, targetName, settings.Limit, window)
+			fs = append(fs, filters.After(rl, deps...))
+		}
+
+		if len(fs) == 0 {
+			continue
+		}
+
+		chain, err := filters.NewChain(fs...)
+		if err != nil {
+			return nil, fmt.Errorf("target %s: %w", targetName, err)
+		}
+		chains[targetName] = chain
+	}
+
+	return chains, nil
+}
+
+// summarySettings are the settings of the configured summary processor, the
+// base of per-target summaries that only swap the model.
+func (s *State) summarySettings() config.SummarySettings {
+	for _, procCfg := range s.Config.Processors {
+		if procCfg.Type == names.Summary {
+			return procCfg.Settings.SummarySettings
+		}
+	}
+	return config.SummarySettings{}
+}
+
 func (s *State) createSource(name string, cfg config.SourceConfig) types.Source {
 	maxItems := cfg.Settings.MaxItems
 
@@ -302,6 +485,38 @@ func (s *State) createSource(name string, cfg config.SourceConfig) types.Source
 		}
 		return source
 
+	case "reddit":
+		source, err := sources.NewRedditSource(name, cfg.Settings.RedditSettings, maxItems)
+		if err != nil {
+			s.Logger.Error("Failed to create Reddit source", "source", name, "error", err)
+			return nil
+		}
+		return source
+
+	case "github":
+		source, err := sources.NewGitHubSource(name, cfg.Settings.GitHubSettings, maxItems)
+		if err != nil {
+			s.Logger.Error("Failed to create GitHub source", "source", name, "error", err)
+			return nil
+		}
+		return source
+
+	case "arxiv":
+		source, err := sources.NewArxivSource(name, cfg.Settings.ArxivSettings, maxItems)
+		if err != nil {
+			s.Logger.Error("Failed to create arXiv source", "source", name, "error", err)
+			return nil
+		}
+		return source
+
+	case "jsonl":
+		source, err := sources.NewJSONLSource(name, cfg.Settings.Path, maxItems)
+		if err != nil {
+			s.Logger.Error("Failed to create JSONL source", "source", name, "error", err)
+			return nil
+		}
+		return source
+
 	default:
 		return nil
 	}
@@ -336,6 +551,13 @@ func (s *State) createProcessor(cfg config.ProcessorConfig) filters.Processor {
 	case names.EmbedText:
 		return processors.NewEmbedTextProcessor(cfg.Type, cfg.Settings.EmbedTextSettings)
 
+	case names.Limit:
+		return filters.NewLimitFilter(cfg.Settings.Limit)
+
+	case names.Rerank:
+		pc := s.Registry.Get(components.PlatformComponentName).(*components.PlatformComponent)
+		return filters.NewRerankFilter(pc.Reranker(), s.Config.Interests.Keywords, cfg.Settings.RerankSettings)
+
 	default:
 		return nil
 	}
@@ -344,25 +566,106 @@ func (s *State) createProcessor(cfg config.ProcessorConfig) filters.Processor {
 func (s *State) createTarget(name string, cfg config.TargetConfig) types.Target {
 	switch cfg.Type {
 	case "discord":
-		discordCfg := cfg.Settings.DiscordTargetSettings
-		if discordCfg.ChannelID == "" {
+		if cfg.Settings.ChannelID == "" {
 			return nil
 		}
-		return targets.NewDiscordTarget(name, discordCfg.ChannelID, discordCfg.ChannelType, s.Registry)
+		return targets.NewDiscordTarget(name, cfg.Settings, s.Registry, s.Logger)
 
 	case "feed":
 		return targets.NewFeedTarget(name, s.Registry)
 
 	case "bluesky":
 		bskyCfg := cfg.Settings.BlueskyTargetSettings
-		return targets.NewBlueskyTarget(name, bskyCfg.Languages, s.Registry)
+		return targets.NewBlueskyTarget(name, bskyCfg, s.Registry)
 
 	case "telegram":
 		tgCfg := cfg.Settings.TelegramTargetSettings
 		if tgCfg.ChatID == 0 {
 			return nil
 		}
-		return targets.NewTelegramTarget(name, tgCfg.ChatID, s.Registry)
+		return targets.NewTelegramTarget(name, tgCfg, s.Registry)
+
+	case "webhook":
+		target, err := targets.NewWebhookTarget(name, cfg.Settings)
+		if err != nil {
+			s.Logger.Error("Failed to create webhook target", "target", name, "error", err)
+			return nil
+		}
+		return target
+
+	case "mastodon":
+		target, err := targets.NewMastodonTarget(name, cfg.Settings, s.Registry)
+		if err != nil {
+			s.Logger.Error("Failed to create mastodon target", "target", name, "error", err)
+			return nil
+		}
+		return target
+
+	case "markdown":
+		target, err := targets.NewMarkdownTarget(name, cfg.Settings)
+		if err != nil {
+			s.Logger.Error("Failed to create markdown target", "target", name, "error", err)
+			return nil
+		}
+		return target
+
+	case "matrix":
+		target, err := targets.NewMatrixTarget(name, cfg.Settings, s.Registry)
+		if err != nil {
+			s.Logger.Error("Failed to create matrix target", "target", name, "error", err)
+			return nil
+		}
+		return target
+
+	case "ntfy", "gotify":
+		target, err := targets.NewPushTarget(name, cfg.Type, cfg.Settings)
+		if err != nil {
+			s.Logger.Error("Failed to create push target", "target", name, "type", cfg.Type, "error", err)
+			return nil
+		}
+		return target
+
+	case "slack":
+		target, err := targets.NewSlackTarget(name, cfg.Settings)
+		if err != nil {
+			s.Logger.Error("Failed to create slack target", "target", name, "error", err)
+			return nil
+		}
+		return target
+
+	case "email":
+		digests := queue.NewDigestQueue(s.RedisConn.Client(), s.Queue.Prefix())
+		target, err := targets.NewEmailTarget(name, cfg.Settings, s.Registry, digests, s.Logger)
+		if err != nil {
+			s.Logger.Error("Failed to create email target", "target", name, "error", err)
+			return nil
+		}
+		return target
+
+	case "wallabag", "linkding", "readeck":
+		links := queue.NewLinkIndex(s.RedisConn.Client(), s.Queue.Prefix())
+		target, err := targets.NewReadLaterTarget(name, cfg.Type, cfg.Settings, links)
+		if err != nil {
+			s.Logger.Error("Failed to create read-later target", "target", name, "type", cfg.Type, "error", err)
+			return nil
+		}
+		return target
+
+	case "jsonl":
+		target, err := targets.NewJSONLTarget(name, cfg.Settings)
+		if err != nil {
+			s.Logger.Error("Failed to create JSONL target", "target", name, "error", err)
+			return nil
+		}
+		return target
+
+	case "static_site":
+		target, err := targets.NewStaticSiteTarget(name, cfg.Settings, s.Registry, s.Logger)
+		if err != nil {
+			s.Logger.Error("Failed to create static site target", "target", name, "error", err)
+			return nil
+		}
+		return target
 
 	default:
 		return nil
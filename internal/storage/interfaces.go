@@ -8,6 +8,7 @@ import (
 
 type StorageInterface interface {
 	Entries() EntryStore
+	Outbox() OutboxStore
 	Close(ctx context.Context) error
 }
 
@@ -24,6 +25,9 @@ type Item interface {
 	GetAuthor() string
 	GetImageURL() string
 	GetMatchedKeywords() string
+	GetRoute() string
+	GetScore() float64
+	GetMetadata() map[string]any
 }
 
 type FeedEntry struct {
@@ -36,12 +40,52 @@ type FeedEntry struct {
 	Source          string
 	ImageURL        string
 	MatchedKeywords string
+	Metadata        map[string]any
 	Hash            string
 	EntryTimestamp  time.Time
 	PublishedAt     time.Time
 	CreatedAt       time.Time
 }
 
+// Published is the record of an item delivered to a target. Result holds
+// the remote identifiers from the target's PublishResult metadata; numbers
+// come back as float64 after the JSON round trip.
+type Published struct {
+	ItemID      string
+	Target      string
+	Result      map[string]any
+	PublishedAt time.Time
+}
+
+// PublishedEntry is a stored entry together with the route it came from and
+// every target it was published to.
+type PublishedEntry struct {
+	Entry     FeedEntry
+	Route     string
+	Published []Published
+}
+
+// FeedPost is a post a target made for an entry, identified by the URI in
+// its publish result.
+type FeedPost struct {
+	ItemID      string
+	URI         string
+	Score       float64
+	PublishedAt time.Time
+}
+
+// FeedPostQuery selects a page of the posts made by Target since Since,
+// newest or best scored first. Interests, when set, keep the entries that
+// matched one of them. After is the last post of the previous page.
+type FeedPostQuery struct {
+	Target    string
+	Interests []string
+	Since     time.Time
+	ByScore   bool
+	After     *FeedPost
+	Limit     int
+}
+
 type PaginationResult struct {
 	Entries     []FeedEntry
 	Total       int
@@ -56,8 +100,13 @@ type EntryStore interface {
 	Store(ctx context.Context, item Item) error
 	Exists(ctx context.Context, id string) (bool, error)
 	ExistsByHash(ctx context.Context, hashes []string) ([]string, error)
-	MarkPublished(ctx context.Context, itemID, target string) error
+	MarkPublished(ctx context.Context, itemID, target string, result map[string]any) error
 	IsPublished(ctx context.Context, itemID, target string) (bool, error)
+	GetPublished(ctx context.Context, itemID, target string) (*Published, error)
+	ListPublished(ctx context.Context, itemID string) ([]Published, error)
+	ListPublishedSince(ctx context.Context, since time.Time, limit int) ([]PublishedEntry, error)
+	ListFeedPosts(ctx context.Context, query FeedPostQuery) ([]FeedPost, error)
+	SetTitle(ctx context.Context, id, title string) error
 	InsertEntry(ctx context.Context, id, title string, link *url.URL, description, content, author, source, imageURL, matchedKeywords string, publishedAt time.Time) error
 	ListRecentEntries(ctx context.Context, limit int) ([]FeedEntry, error)
 	ListPublishedEntries(ctx context.Context, target string, limit int) ([]FeedEntry, error)
@@ -66,3 +115,34 @@ type EntryStore interface {
 	SetEmbedding(ctx context.Context, id string, embedding []float32) error
 	FindNearestEmbedding(ctx context.Context, embedding []float32, threshold float64, since time.Time) (bool, error)
 }
+
+const (
+	OutboxPending = "pending"
+	OutboxDead    = "dead"
+)
+
+// OutboxEntry is a delivery of one item to one target that failed and is
+// waiting to be retried. Payload holds the item as JSON.
+type OutboxEntry struct {
+	ItemID        string
+	Target        string
+	Payload       []byte
+	Attempts      int
+	Status        string
+	LastError     string
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type OutboxStore interface {
+	Enqueue(ctx context.Context, itemID, target string, payload []byte, lastError string, nextAttempt time.Time) error
+	Defer(ctx context.Context, itemID, target string, payload []byte, reason string, nextAttempt time.Time) error
+	Has(ctx context.Context, itemID, target string) (bool, error)
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]OutboxEntry, error)
+	Complete(ctx context.Context, itemID, target string) error
+	Fail(ctx context.Context, itemID, target, lastError string, nextAttempt time.Time) error
+	Kill(ctx context.Context, itemID, target, lastError string) error
+	ListDead(ctx context.Context, limit int) ([]OutboxEntry, error)
+	Requeue(ctx context.Context, itemID, target string) (int64, error)
+}
@@ -5,8 +5,10 @@ import (
 	"cartero/internal/utils/hash"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/pgvector/pgvector-go"
@@ -24,9 +26,15 @@ func (s *entryStore) Store(ctx context.Context, item storage.Item) error {
 	h := hash.HashURL(item.GetURL())
 	publishedAt := sql.NullTime{Valid: !item.GetTimestamp().IsZero(), Time: item.GetTimestamp()}
 
+	// Metadata that doesn't encode is left out rather than failing the store.
+	var metadata []byte
+	if m := item.GetMetadata(); len(m) > 0 {
+		metadata, _ = json.Marshal(m)
+	}
+
 	query := `
-		INSERT INTO feed_entries (id, hash, source, entry_timestamp, title, link, description, content, author, image_url, matched_keywords, published_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		INSERT INTO feed_entries (id, hash, source, entry_timestamp, title, link, description, content, author, image_url, matched_keywords, published_at, route, score, metadata)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 		ON CONFLICT(id) DO UPDATE SET
 			title = EXCLUDED.title,
 			link = EXCLUDED.link,
@@ -35,13 +43,16 @@ func (s *entryStore) Store(ctx context.Context, item storage.Item) error {
 			author = EXCLUDED.author,
 			image_url = EXCLUDED.image_url,
 			matched_keywords = EXCLUDED.matched_keywords,
-			published_at = EXCLUDED.published_at
+			published_at = EXCLUDED.published_at,
+			route = EXCLUDED.route,
+			score = EXCLUDED.score,
+			metadata = EXCLUDED.metadata
 	`
 
 	_, err := s.db.ExecContext(ctx, query,
 		item.GetID(), h, item.GetSource(), item.GetTimestamp(), item.GetTitle(),
 		item.GetLink().String(), item.GetDescription(), item.GetFeedContent(), item.GetAuthor(),
-		item.GetImageURL(), item.GetMatchedKeywords(), publishedAt,
+		item.GetImageURL(), item.GetMatchedKeywords(), publishedAt, item.GetRoute(), item.GetScore(), metadata,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to store entry: %w", err)
@@ -120,14 +131,22 @@ func (s *entryStore) ExistsByHash(ctx context.Context, hashes []string) ([]strin
 	return existing, rows.Err()
 }
 
-func (s *entryStore) MarkPublished(ctx context.Context, itemID, target string) error {
+func (s *entryStore) MarkPublished(ctx context.Context, itemID, target string, result map[string]any) error {
+	if result == nil {
+		result = map[string]any{}
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode publish result: %w", err)
+	}
+
 	query := `
-		INSERT INTO published (item_id, target)
-		VALUES ($1, $2)
-		ON CONFLICT(item_id, target) DO NOTHING
+		INSERT INTO published (item_id, target, result)
+		VALUES ($1, $2, $3)
+		ON CONFLICT(item_id, target) DO UPDATE SET result = EXCLUDED.result
 	`
 
-	_, err := s.db.ExecContext(ctx, query, itemID, target)
+	_, err = s.db.ExecContext(ctx, query, itemID, target, data)
 	if err != nil {
 		return fmt.Errorf("failed to mark as published: %w", err)
 	}
@@ -135,6 +154,216 @@ func (s *entryStore) MarkPublished(ctx context.Context, itemID, target string) e
 	return nil
 }
 
+func (s *entryStore) GetPublished(ctx context.Context, itemID, target string) (*storage.Published, error) {
+	query := `
+		SELECT item_id, target, result, published_at
+		FROM published
+		WHERE item_id = $1 AND target = $2
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, itemID, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query published: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	published, err := scanPublished(rows)
+	if err != nil || len(published) == 0 {
+		return nil, err
+	}
+	return &published[0], nil
+}
+
+func (s *entryStore) ListPublished(ctx context.Context, itemID string) ([]storage.Published, error) {
+	query := `
+		SELECT item_id, target, result, published_at
+		FROM published
+		WHERE item_id = $1
+		ORDER BY published_at
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query published: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanPublished(rows)
+}
+
+// ListPublishedSince returns entries published to any target since the
+// given time, newest first, with all of their publish records.
+func (s *entryStore) ListPublishedSince(ctx context.Context, since time.Time, limit int) ([]storage.PublishedEntry, error) {
+	query := `
+		SELECT fe.id, fe.title, COALESCE(fe.link, ''), COALESCE(fe.description, ''), COALESCE(fe.author, ''), fe.source,
+		       COALESCE(fe.image_url, ''), COALESCE(fe.matched_keywords, ''), COALESCE(fe.entry_timestamp, fe.created_at, NOW()), COALESCE(fe.route, ''),
+		       fe.metadata, p.target, p.result, p.published_at
+		FROM feed_entries fe
+		JOIN published p ON p.item_id = fe.id
+		WHERE fe.id IN (
+			SELECT item_id FROM published
+			WHERE published_at >= $1
+			GROUP BY item_id
+			ORDER BY MAX(published_at) DESC
+			LIMIT $2
+		)
+		ORDER BY fe.id, p.published_at
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query published entries: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []storage.PublishedEntry
+	for rows.Next() {
+		var e storage.PublishedEntry
+		var p storage.Published
+		var metadata, result []byte
+		var publishedAt sql.NullTime
+
+		err := rows.Scan(
+			&e.Entry.ID,
+			&e.Entry.Title,
+			&e.Entry.Link,
+			&e.Entry.Description,
+			&e.Entry.Author,
+			&e.Entry.Source,
+			&e.Entry.ImageURL,
+			&e.Entry.MatchedKeywords,
+			&e.Entry.EntryTimestamp,
+			&e.Route,
+			&metadata,
+			&p.Target,
+			&result,
+			&publishedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan published entry: %w", err)
+		}
+		if err := json.Unmarshal(result, &p.Result); err != nil {
+			return nil, fmt.Errorf("failed to decode publish result: %w", err)
+		}
+		if len(metadata) > 0 {
+			if err := json.Unmarshal(metadata, &e.Entry.Metadata); err != nil {
+				return nil, fmt.Errorf("failed to decode entry metadata: %w", err)
+			}
+		}
+		p.ItemID = e.Entry.ID
+		if publishedAt.Valid {
+			p.PublishedAt = publishedAt.Time
+		}
+
+		if n := len(out); n > 0 && out[n-1].Entry.ID == e.Entry.ID {
+			out[n-1].Published = append(out[n-1].Published, p)
+			continue
+		}
+		e.Published = []storage.Published{p}
+		out = append(out, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return out, nil
+}
+
+// ListFeedPosts returns a page of the posts q.Target made, as recorded by
+// the uri in their publish results. Pages continue strictly after q.After
+// in the same order, so posts published meanwhile don't shift them.
+func (s *entryStore) ListFeedPosts(ctx context.Context, q storage.FeedPostQuery) ([]storage.FeedPost, error) {
+	var afterTime time.Time
+	var afterID string
+	var afterScore float64
+	if q.After != nil {
+		afterTime, afterID, afterScore = q.After.PublishedAt, q.After.ItemID, q.After.Score
+	}
+
+	interests := make([]string, 0, len(q.Interests))
+	for _, interest := range q.Interests {
+		interests = append(interests, strings.ToLower(strings.TrimSpace(interest)))
+	}
+
+	args := []any{q.Target, q.Since, interests, q.After != nil, afterTime, afterID, q.Limit}
+	order := `p.published_at DESC, p.item_id DESC`
+	after := `(p.published_at, p.item_id) < ($5, $6)`
+	if q.ByScore {
+		order = `COALESCE(fe.score, 0) DESC, ` + order
+		after = `(COALESCE(fe.score, 0), p.published_at, p.item_id) < ($8, $5, $6)`
+		args = append(args, afterScore)
+	}
+
+	query := `
+		SELECT p.item_id, p.result->>'uri', COALESCE(fe.score, 0), p.published_at
+		FROM published p
+		JOIN feed_entries fe ON fe.id = p.item_id
+		WHERE p.target = $1
+		  AND COALESCE(p.result->>'uri', '') <> ''
+		  AND p.published_at >= $2
+		  AND (cardinality($3::text[]) = 0 OR regexp_split_to_array(lower(COALESCE(fe.matched_keywords, '')), '\s*,\s*') && $3::text[])
+		  AND ($4 = FALSE OR ` + after + `)
+		ORDER BY ` + order + `
+		LIMIT $7
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query feed posts: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []storage.FeedPost
+	for rows.Next() {
+		var post storage.FeedPost
+		if err := rows.Scan(&post.ItemID, &post.URI, &post.Score, &post.PublishedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan feed post: %w", err)
+		}
+		out = append(out, post)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return out, nil
+}
+
+func (s *entryStore) SetTitle(ctx context.Context, id, title string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE feed_entries SET title = $2 WHERE id = $1`, id, title)
+	if err != nil {
+		return fmt.Errorf("failed to update title: %w", err)
+	}
+	return nil
+}
+
+func scanPublished(rows *sql.Rows) ([]storage.Published, error) {
+	var out []storage.Published
+	for rows.Next() {
+		var p storage.Published
+		var result []byte
+		var publishedAt sql.NullTime
+
+		if err := rows.Scan(&p.ItemID, &p.Target, &result, &publishedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan published: %w", err)
+		}
+		if err := json.Unmarshal(result, &p.Result); err != nil {
+			return nil, fmt.Errorf("failed to decode publish result: %w", err)
+		}
+		if publishedAt.Valid {
+			p.PublishedAt = publishedAt.Time
+		}
+		out = append(out, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return out, nil
+}
+
 func (s *entryStore) IsPublished(ctx context.Context, itemID, target string) (bool, error) {
 	var exists bool
 	err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM published WHERE item_id = $1 AND target = $2)`, itemID, target).Scan(&exists)
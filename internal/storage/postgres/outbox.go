@@ -0,0 +1,200 @@
+package postgres
+
+import (
+	"cartero/internal/storage"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type outboxStore struct {
+	db *sql.DB
+}
+
+func newOutboxStore(db *sql.DB) storage.OutboxStore {
+	return &outboxStore{db: db}
+}
+
+const outboxColumns = `item_id, target, payload, attempts, status, COALESCE(last_error, ''), next_attempt_at, created_at, updated_at`
+
+func (s *outboxStore) Enqueue(ctx context.Context, itemID, target string, payload []byte, lastError string, nextAttempt time.Time) error {
+	query := `
+		INSERT INTO publish_outbox (item_id, target, payload, attempts, status, last_error, next_attempt_at)
+		VALUES ($1, $2, $3, 1, 'pending', $4, $5)
+		ON CONFLICT(item_id, target) DO UPDATE SET
+			payload = EXCLUDED.payload,
+			attempts = publish_outbox.attempts + 1,
+			last_error = EXCLUDED.last_error,
+			next_attempt_at = EXCLUDED.next_attempt_at,
+			updated_at = NOW()
+	`
+
+	_, err := s.db.ExecContext(ctx, query, itemID, target, payload, lastError, nextAttempt)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue delivery: %w", err)
+	}
+	return nil
+}
+
+// Defer schedules a delivery that is held back rather than failed, so it
+// doesn't count as an attempt.
+func (s *outboxStore) Defer(ctx context.Context, itemID, target string, payload []byte, reason string, nextAttempt time.Time) error {
+	query := `
+		INSERT INTO publish_outbox (item_id, target, payload, attempts, status, last_error, next_attempt_at)
+		VALUES ($1, $2, $3, 0, 'pending', $4, $5)
+		ON CONFLICT(item_id, target) DO UPDATE SET
+			payload = EXCLUDED.payload,
+			last_error = EXCLUDED.last_error,
+			next_attempt_at = EXCLUDED.next_attempt_at,
+			updated_at = NOW()
+	`
+
+	_, err := s.db.ExecContext(ctx, query, itemID, target, payload, reason, nextAttempt)
+	if err != nil {
+		return fmt.Errorf("failed to defer delivery: %w", err)
+	}
+	return nil
+}
+
+func (s *outboxStore) Has(ctx context.Context, itemID, target string) (bool, error) {
+	var exists bool
+	err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM publish_outbox WHERE item_id = $1 AND target = $2)`, itemID, target).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check outbox: %w", err)
+	}
+	return exists, nil
+}
+
+// Claim returns up to limit due deliveries and pushes their next attempt
+// past the lease, so a concurrent worker won't pick them up meanwhile.
+func (s *outboxStore) Claim(ctx context.Context, limit int, lease time.Duration) ([]storage.OutboxEntry, error) {
+	query := `
+		UPDATE publish_outbox SET
+			next_attempt_at = NOW() + make_interval(secs => $2),
+			updated_at = NOW()
+		WHERE (item_id, target) IN (
+			SELECT item_id, target FROM publish_outbox
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + outboxColumns
+
+	rows, err := s.db.QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim deliveries: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanOutbox(rows)
+}
+
+func (s *outboxStore) Complete(ctx context.Context, itemID, target string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM publish_outbox WHERE item_id = $1 AND target = $2`, itemID, target)
+	if err != nil {
+		return fmt.Errorf("failed to complete delivery: %w", err)
+	}
+	return nil
+}
+
+func (s *outboxStore) Fail(ctx context.Context, itemID, target, lastError string, nextAttempt time.Time) error {
+	query := `
+		UPDATE publish_outbox SET
+			attempts = attempts + 1,
+			last_error = $3,
+			next_attempt_at = $4,
+			updated_at = NOW()
+		WHERE item_id = $1 AND target = $2
+	`
+
+	_, err := s.db.ExecContext(ctx, query, itemID, target, lastError, nextAttempt)
+	if err != nil {
+		return fmt.Errorf("failed to record delivery failure: %w", err)
+	}
+	return nil
+}
+
+func (s *outboxStore) Kill(ctx context.Context, itemID, target, lastError string) error {
+	query := `
+		UPDATE publish_outbox SET
+			attempts = attempts + 1,
+			status = 'dead',
+			last_error = $3,
+			updated_at = NOW()
+		WHERE item_id = $1 AND target = $2
+	`
+
+	_, err := s.db.ExecContext(ctx, query, itemID, target, lastError)
+	if err != nil {
+		return fmt.Errorf("failed to dead-letter delivery: %w", err)
+	}
+	return nil
+}
+
+func (s *outboxStore) ListDead(ctx context.Context, limit int) ([]storage.OutboxEntry, error) {
+	query := `SELECT ` + outboxColumns + `
+		FROM publish_outbox
+		WHERE status = 'dead'
+		ORDER BY updated_at DESC
+		LIMIT $1
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dead letters: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanOutbox(rows)
+}
+
+// Requeue moves dead deliveries back to pending with a fresh attempt count.
+// An empty itemID or target matches every dead delivery.
+func (s *outboxStore) Requeue(ctx context.Context, itemID, target string) (int64, error) {
+	query := `
+		UPDATE publish_outbox SET
+			status = 'pending',
+			attempts = 0,
+			next_attempt_at = NOW(),
+			updated_at = NOW()
+		WHERE status = 'dead'
+			AND ($1 = '' OR item_id = $1)
+			AND ($2 = '' OR target = $2)
+	`
+
+	res, err := s.db.ExecContext(ctx, query, itemID, target)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue deliveries: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+func scanOutbox(rows *sql.Rows) ([]storage.OutboxEntry, error) {
+	var entries []storage.OutboxEntry
+	for rows.Next() {
+		var e storage.OutboxEntry
+		err := rows.Scan(
+			&e.ItemID,
+			&e.Target,
+			&e.Payload,
+			&e.Attempts,
+			&e.Status,
+			&e.LastError,
+			&e.NextAttemptAt,
+			&e.CreatedAt,
+			&e.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return entries, nil
+}
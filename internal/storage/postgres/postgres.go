@@ -20,6 +20,7 @@ func init() {
 type PostgresStorage struct {
 	conn    *sql.DB
 	entries storage.EntryStore
+	outbox  storage.OutboxStore
 }
 
 func New(dsn string) (storage.StorageInterface, error) {
@@ -44,6 +45,7 @@ func New(dsn string) (storage.StorageInterface, error) {
 	return &PostgresStorage{
 		conn:    conn,
 		entries: newEntryStore(conn),
+		outbox:  newOutboxStore(conn),
 	}, nil
 }
 
@@ -71,6 +73,10 @@ func (s *PostgresStorage) Entries() storage.EntryStore {
 	return s.entries
 }
 
+func (s *PostgresStorage) Outbox() storage.OutboxStore {
+	return s.outbox
+}
+
 func (s *PostgresStorage) Close(ctx context.Context) error {
 	if s.conn != nil {
 		return s.conn.Close()
@@ -3,11 +3,13 @@ package bluesky
 import (
 	"bytes"
 	"cartero/internal/components"
+	"cartero/internal/config"
 	"cartero/internal/platforms"
 	"cartero/internal/types"
 	"cartero/internal/utils"
 	"context"
 	"fmt"
+	"strings"
 	"text/template"
 
 	"github.com/bluesky-social/indigo/api/atproto"
@@ -16,14 +18,18 @@ import (
 	"github.com/bluesky-social/indigo/xrpc"
 )
 
+// Target posts items to Bluesky. With thread set, the summary follows the
+// post as a reply thread, since it won't fit in the post itself.
 type Target struct {
-	name      string
-	platform  *platforms.BlueskyPlatform
-	languages []string
-	template  *template.Template
+	name        string
+	platform    *platforms.BlueskyPlatform
+	languages   []string
+	thread      bool
+	threadPosts int
+	template    *template.Template
 }
 
-func New(name string, languages []string, registry *components.Registry) *Target {
+func New(name string, settings config.BlueskyTargetSettings, registry *components.Registry) *Target {
 	platformCmp := registry.Get(components.PlatformComponentName).(*components.PlatformComponent)
 
 	tmpl, err := utils.LoadTemplate("templates/bluesky.tmpl")
@@ -31,11 +37,18 @@ func New(name string, languages []string, registry *components.Registry) *Target
 		panic(err.Error())
 	}
 
+	threadPosts := settings.ThreadMaxPosts
+	if threadPosts <= 0 {
+		threadPosts = defaultThreadPosts
+	}
+
 	return &Target{
-		name:      name,
-		platform:  platformCmp.Bluesky(),
-		languages: languages,
-		template:  tmpl,
+		name:        name,
+		platform:    platformCmp.Bluesky(),
+		languages:   settings.Languages,
+		thread:      settings.Thread,
+		threadPosts: threadPosts,
+		template:    tmpl,
 	}
 }
 
@@ -74,18 +87,18 @@ func (t *Target) Publish(ctx context.Context, item *types.Item) (*types.PublishR
 	var resp *atproto.RepoCreateRecord_Output
 	err := t.platform.Do(ctx, func(c *xrpc.Client) error {
 		if post.Embed != nil && post.Embed.ThumbnailURL != "" {
-			blob, blobErr := UploadBlob(ctx, c, post.Embed.ThumbnailURL)
-			if blobErr == nil {
-				AttachThumbnail(bskyPost, blob)
+			img, imgErr := UploadBlob(ctx, c, post.Embed.ThumbnailURL)
+			if imgErr == nil {
+				if embedExternal != nil {
+					AttachThumbnail(bskyPost, img)
+				} else {
+					AttachImage(bskyPost, img, post.Embed.Alt)
+				}
 			}
 		}
 
 		var err error
-		resp, err = atproto.RepoCreateRecord(ctx, c, &atproto.RepoCreateRecord_Input{
-			Collection: "app.bsky.feed.post",
-			Repo:       c.Auth.Did,
-			Record:     &util.LexiconTypeDecoder{Val: bskyPost},
-		})
+		resp, err = t.createPost(ctx, c, bskyPost)
 		return err
 	})
 
@@ -96,15 +109,113 @@ func (t *Target) Publish(ctx context.Context, item *types.Item) (*types.PublishR
 		}, err
 	}
 
+	metadata := map[string]any{
+		"uri": resp.Uri,
+		"cid": resp.Cid,
+	}
+
+	if t.thread {
+		replies, err := t.publishThread(ctx, item, resp)
+		if len(replies) > 0 {
+			metadata["reply_uris"] = replies
+		}
+		// The root post is out, so a failed reply doesn't fail the publish.
+		if err != nil {
+			metadata["error"] = fmt.Sprintf("failed to post reply thread: %v", err)
+		}
+	}
+
 	return &types.PublishResult{
-		Success: true,
-		Metadata: map[string]any{
-			"uri": resp.Uri,
-			"cid": resp.Cid,
-		},
+		Success:  true,
+		Metadata: metadata,
 	}, nil
 }
 
+// publishThread replies to the root post with the item's summary, each
+// reply to the one before it. It returns the URIs of the replies posted.
+func (t *Target) publishThread(ctx context.Context, item *types.Item, root *atproto.RepoCreateRecord_Output) ([]string, error) {
+	rootRef := &atproto.RepoStrongRef{Uri: root.Uri, Cid: root.Cid}
+	parentRef := rootRef
+
+	var uris []string
+	for _, text := range threadPosts(threadText(item), maxPostRunes, t.threadPosts) {
+		reply := BuildPost(RichText{Text: text}, nil, t.languages)
+		reply.Reply = &bsky.FeedPost_ReplyRef{Root: rootRef, Parent: parentRef}
+
+		var resp *atproto.RepoCreateRecord_Output
+		err := t.platform.Do(ctx, func(c *xrpc.Client) error {
+			var err error
+			resp, err = t.createPost(ctx, c, reply)
+			return err
+		})
+		if err != nil {
+			return uris, err
+		}
+
+		uris = append(uris, resp.Uri)
+		parentRef = &atproto.RepoStrongRef{Uri: resp.Uri, Cid: resp.Cid}
+	}
+	return uris, nil
+}
+
+func (t *Target) createPost(ctx context.Context, c *xrpc.Client, post *bsky.FeedPost) (*atproto.RepoCreateRecord_Output, error) {
+	return atproto.RepoCreateRecord(ctx, c, &atproto.RepoCreateRecord_Input{
+		Collection: "app.bsky.feed.post",
+		Repo:       c.Auth.Did,
+		Record:     &util.LexiconTypeDecoder{Val: post},
+	})
+}
+
+// Delete removes a published post and its reply thread. Bluesky posts can't
+// be edited, so this is also how title changes are handled when
+// delete_on_change is set.
+func (t *Target) Delete(ctx context.Context, item *types.Item, published map[string]any) error {
+	uri, _ := published["uri"].(string)
+	if _, err := postRkey(uri); err != nil {
+		return err
+	}
+
+	var uris []string
+	switch replies := published["reply_uris"].(type) {
+	case []string:
+		uris = append(uris, replies...)
+	case []any:
+		for _, r := range replies {
+			if s, ok := r.(string); ok {
+				uris = append(uris, s)
+			}
+		}
+	}
+	uris = append(uris, uri)
+
+	for _, u := range uris {
+		rkey, err := postRkey(u)
+		if err != nil {
+			return err
+		}
+		err = t.platform.Do(ctx, func(c *xrpc.Client) error {
+			_, err := atproto.RepoDeleteRecord(ctx, c, &atproto.RepoDeleteRecord_Input{
+				Collection: "app.bsky.feed.post",
+				Repo:       c.Auth.Did,
+				Rkey:       rkey,
+			})
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func postRkey(uri string) (string, error) {
+	rkey := uri[strings.LastIndex(uri, "/")+1:]
+	if !strings.HasPrefix(uri, "at://") || rkey == "" {
+		return "", fmt.Errorf("publish result has no valid uri: %q", uri)
+	}
+	return rkey, nil
+}
+
 func (t *Target) Shutdown(ctx context.Context) error {
 	return nil
 }
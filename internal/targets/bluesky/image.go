@@ -0,0 +1,118 @@
+package bluesky
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+)
+
+var jpegQualities = []int{85, 75, 60}
+
+const (
+	// scaleStep shrinks each side between rounds of re-encoding.
+	scaleStep = 0.75
+	maxRounds = 8
+	// maxPixels bounds the images decoded for re-encoding, as a decoded
+	// image takes four bytes per pixel whatever its file size.
+	maxPixels = 25_000_000
+)
+
+// fitImage re-encodes an image that is over maxSize bytes as JPEG, lowering
+// the quality and then the resolution until it fits. It returns the data to
+// upload and the image's dimensions.
+//
+// Images that already fit are passed through as they are, whatever their
+// format; the dimensions are zero when Go can't read it.
+func fitImage(data []byte, maxSize int) ([]byte, int, int, error) {
+	if len(data) <= maxSize {
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return data, 0, 0, nil
+		}
+		return data, cfg.Width, cfg.Height, nil
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("bluesky: decode image: %w", err)
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, 0, 0, fmt.Errorf("bluesky: image of %dx%d pixels is too large to re-encode", cfg.Width, cfg.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("bluesky: decode image: %w", err)
+	}
+
+	flat := flatten(img)
+	for round := 0; round < maxRounds; round++ {
+		for _, quality := range jpegQualities {
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: quality}); err != nil {
+				return nil, 0, 0, fmt.Errorf("bluesky: encode image: %w", err)
+			}
+			if buf.Len() <= maxSize {
+				b := flat.Bounds()
+				return buf.Bytes(), b.Dx(), b.Dy(), nil
+			}
+		}
+
+		b := flat.Bounds()
+		w, h := int(float64(b.Dx())*scaleStep), int(float64(b.Dy())*scaleStep)
+		if w < 1 || h < 1 {
+			break
+		}
+		flat = downscale(flat, w, h)
+	}
+	return nil, 0, 0, fmt.Errorf("bluesky: image does not fit in %d bytes", maxSize)
+}
+
+// flatten draws img onto white, since JPEG has no transparency.
+func flatten(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(out, out.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(out, out.Bounds(), img, b.Min, draw.Over)
+	return out
+}
+
+// downscale resizes src to w×h by averaging the source pixels that fall
+// into each destination pixel.
+func downscale(src *image.RGBA, w, h int) *image.RGBA {
+	sb := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		y0 := y * sb.Dy() / h
+		y1 := max((y+1)*sb.Dy()/h, y0+1)
+		for x := 0; x < w; x++ {
+			x0 := x * sb.Dx() / w
+			x1 := max((x+1)*sb.Dx()/w, x0+1)
+
+			var r, g, bl, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					i := src.PixOffset(sx, sy)
+					r += uint32(src.Pix[i])
+					g += uint32(src.Pix[i+1])
+					bl += uint32(src.Pix[i+2])
+					a += uint32(src.Pix[i+3])
+					n++
+				}
+			}
+
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(bl / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,120 @@
+package bluesky
+
+import (
+	"cartero/internal/types"
+	"strings"
+	"unicode"
+)
+
+const defaultThreadPosts = 3
+
+// threadText is what the reply thread is made of: the summary, or the
+// article description when there is none.
+func threadText(item *types.Item) string {
+	if summary := item.GetSummary(); strings.TrimSpace(summary) != "" {
+		return summary
+	}
+	if a := item.GetArticle(); a != nil {
+		return a.Description
+	}
+	return ""
+}
+
+// splitSentences breaks text into sentences, keeping the punctuation and
+// treating line breaks as boundaries too.
+func splitSentences(text string) []string {
+	var sentences []string
+	runes := []rune(strings.TrimSpace(text))
+
+	start := 0
+	for i, r := range runes {
+		end := false
+		switch {
+		case r == '\n':
+			end = true
+		case r == '.' || r == '!' || r == '?' || r == '…':
+			end = i+1 == len(runes) || unicode.IsSpace(runes[i+1])
+		}
+		if !end {
+			continue
+		}
+		if s := strings.TrimSpace(string(runes[start : i+1])); s != "" {
+			sentences = append(sentences, s)
+		}
+		start = i + 1
+	}
+	if s := strings.TrimSpace(string(runes[start:])); s != "" {
+		sentences = append(sentences, s)
+	}
+	return sentences
+}
+
+// threadPosts packs text into at most maxPosts posts of up to limit runes,
+// breaking between sentences where possible and between words otherwise.
+// Text that doesn't fit ends the last post with an ellipsis.
+func threadPosts(text string, limit, maxPosts int) []string {
+	var pieces []string
+	for _, sentence := range splitSentences(text) {
+		if len([]rune(sentence)) <= limit {
+			pieces = append(pieces, sentence)
+			continue
+		}
+		pieces = append(pieces, splitWords(sentence, limit)...)
+	}
+
+	var posts []string
+	var current string
+	for _, piece := range pieces {
+		switch {
+		case current == "":
+			current = piece
+		case len([]rune(current))+1+len([]rune(piece)) <= limit:
+			current += " " + piece
+		default:
+			posts = append(posts, current)
+			current = piece
+		}
+	}
+	if current != "" {
+		posts = append(posts, current)
+	}
+
+	if len(posts) > maxPosts {
+		posts = posts[:maxPosts]
+		last := []rune(posts[maxPosts-1])
+		if len(last) >= limit {
+			last = last[:limit-1]
+		}
+		posts[maxPosts-1] = strings.TrimRightFunc(string(last), unicode.IsSpace) + "…"
+	}
+	return posts
+}
+
+func splitWords(s string, limit int) []string {
+	var out []string
+	var current []rune
+	for _, word := range strings.Fields(s) {
+		w := []rune(word)
+		for len(w) > limit {
+			if len(current) > 0 {
+				out = append(out, string(current))
+				current = nil
+			}
+			out = append(out, string(w[:limit]))
+			w = w[limit:]
+		}
+		switch {
+		case len(current) == 0:
+			current = w
+		case len(current)+1+len(w) <= limit:
+			current = append(append(current, ' '), w...)
+		default:
+			out = append(out, string(current))
+			current = w
+		}
+	}
+	if len(current) > 0 {
+		out = append(out, string(current))
+	}
+	return out
+}
@@ -33,6 +33,10 @@ type EmbedData struct {
 	Title        string `json:"title"`
 	Description  string `json:"description"`
 	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+	// Alt describes the image when it is posted as an image embed, which
+	// is only the case when there is no link to make a card from. Link
+	// cards have no alt text, so it is ignored for them.
+	Alt string `json:"alt,omitempty"`
 }
 
 type RichText struct {
@@ -188,9 +192,22 @@ func BuildPost(richText RichText, embedExternal *bsky.EmbedExternal_External, la
 	return post
 }
 
-const maxBlobSize = 976 * 1024
+const (
+	maxBlobSize = 976 * 1024
+	// maxImageDownload caps what is read of an image before re-encoding.
+	maxImageDownload = 16 * 1024 * 1024
+)
+
+// Image is an uploaded image blob with its dimensions.
+type Image struct {
+	Blob   *util.LexBlob
+	Width  int
+	Height int
+}
 
-func UploadBlob(ctx context.Context, c *xrpc.Client, imageURL string) (*util.LexBlob, error) {
+// UploadBlob fetches an image and uploads it. Images over the blob limit
+// are re-encoded to fit rather than dropped.
+func UploadBlob(ctx context.Context, c *xrpc.Client, imageURL string) (*Image, error) {
 	if imageURL == "" {
 		return nil, nil
 	}
@@ -213,13 +230,17 @@ func UploadBlob(ctx context.Context, c *xrpc.Client, imageURL string) (*util.Lex
 		return nil, fmt.Errorf("failed to fetch image: %s", resp.Status)
 	}
 
-	data, readErr := io.ReadAll(resp.Body)
+	data, readErr := io.ReadAll(io.LimitReader(resp.Body, maxImageDownload+1))
 	if readErr != nil {
 		return nil, readErr
 	}
+	if len(data) > maxImageDownload {
+		return nil, fmt.Errorf("image too large")
+	}
 
-	if len(data) > maxBlobSize {
-		return nil, nil
+	data, width, height, fitErr := fitImage(data, maxBlobSize)
+	if fitErr != nil {
+		return nil, fitErr
 	}
 
 	blobResp, blobErr := atproto.RepoUploadBlob(ctx, c, bytes.NewReader(data))
@@ -227,11 +248,28 @@ func UploadBlob(ctx context.Context, c *xrpc.Client, imageURL string) (*util.Lex
 		return nil, blobErr
 	}
 
-	return blobResp.Blob, nil
+	return &Image{Blob: blobResp.Blob, Width: width, Height: height}, nil
 }
 
-func AttachThumbnail(post *bsky.FeedPost, blob *util.LexBlob) {
-	if post.Embed != nil && post.Embed.EmbedExternal != nil && blob != nil {
-		post.Embed.EmbedExternal.External.Thumb = blob
+// AttachThumbnail sets the link card's thumbnail. Cards have no alt text;
+// their title describes them.
+func AttachThumbnail(post *bsky.FeedPost, img *Image) {
+	if post.Embed != nil && post.Embed.EmbedExternal != nil && img != nil {
+		post.Embed.EmbedExternal.External.Thumb = img.Blob
+	}
+}
+
+// AttachImage embeds img with alt text, for posts without a link card.
+func AttachImage(post *bsky.FeedPost, img *Image, alt string) {
+	if img == nil {
+		return
+	}
+
+	image := &bsky.EmbedImages_Image{Alt: alt, Image: img.Blob}
+	if img.Width > 0 && img.Height > 0 {
+		image.AspectRatio = &bsky.EmbedDefs_AspectRatio{Width: int64(img.Width), Height: int64(img.Height)}
+	}
+	post.Embed = &bsky.FeedPost_Embed{
+		EmbedImages: &bsky.EmbedImages{Images: []*bsky.EmbedImages_Image{image}},
 	}
 }
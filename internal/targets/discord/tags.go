@@ -0,0 +1,176 @@
+package discord
+
+import (
+	"cartero/internal/types"
+	"cartero/internal/utils"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// maxAppliedTags and maxForumTags are Discord's limits per thread and
+	// per forum; tag names are at most maxTagName characters.
+	maxAppliedTags = 5
+	maxForumTags   = 20
+	maxTagName     = 20
+)
+
+// forumTags resolves an item's matched interest and source to the forum's
+// tags. Names go through the configured mapping first, which may name a tag
+// or give its ID; otherwise they are matched by slug, exactly or by whole
+// words, so "go" matches "go-tips" but not "google". Unmatched names become
+// new tags when create is set.
+type forumTags struct {
+	session   *discordgo.Session
+	channelID string
+	mapping   map[string]string
+	create    bool
+	logger    *slog.Logger
+
+	mu        sync.Mutex
+	available []discordgo.ForumTag
+}
+
+func newForumTags(session *discordgo.Session, channelID string, mapping map[string]string, create bool, logger *slog.Logger) *forumTags {
+	normalized := make(map[string]string, len(mapping))
+	for name, tag := range mapping {
+		normalized[utils.Slug(name)] = tag
+	}
+	return &forumTags{session: session, channelID: channelID, mapping: normalized, create: create, logger: logger}
+}
+
+func (f *forumTags) load() error {
+	channel, err := f.session.Channel(f.channelID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch forum channel: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.available = channel.AvailableTags
+	return nil
+}
+
+// For returns the IDs of the tags to apply to item's thread. A tag that
+// can't be created is left out rather than failing the post.
+func (f *forumTags) For(item *types.Item) []string {
+	names := append(strings.Split(item.GetMatchedKeywords(), ","), item.GetSource())
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var ids []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if len(ids) == maxAppliedTags {
+			break
+		}
+		slug := utils.Slug(name)
+		if slug == "" {
+			continue
+		}
+
+		want := strings.TrimSpace(name)
+		if mapped, ok := f.mapping[slug]; ok {
+			if mapped == "" {
+				continue
+			}
+			want = mapped
+		}
+
+		id := f.match(want)
+		if id == "" && f.create {
+			var err error
+			if id, err = f.add(want); err != nil {
+				f.logger.Warn("discord: posting without tag", "channel_id", f.channelID, "tag", want, "error", err)
+			}
+		}
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// match finds the tag with ID want, or the tag whose name matches it best.
+func (f *forumTags) match(want string) string {
+	slug := utils.Slug(want)
+
+	best, bestScore := "", 0
+	for _, tag := range f.available {
+		if tag.ID == want {
+			return tag.ID
+		}
+
+		tagSlug := utils.Slug(tag.Name)
+		var score int
+		switch {
+		case tagSlug == "":
+			continue
+		case tagSlug == slug:
+			return tag.ID
+		case containsWords(slug, tagSlug), containsWords(tagSlug, slug):
+			// The tag closest in length wins.
+			score = 100 - abs(len(slug)-len(tagSlug))
+		default:
+			continue
+		}
+		if score > bestScore {
+			best, bestScore = tag.ID, score
+		}
+	}
+	return best
+}
+
+// add creates a tag on the forum. Discord replaces the whole tag list, so
+// the existing tags are sent along.
+func (f *forumTags) add(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if r := []rune(name); len(r) > maxTagName {
+		name = string(r[:maxTagName])
+	}
+	if name == "" || len(f.available) >= maxForumTags {
+		return "", nil
+	}
+
+	tags := append(append([]discordgo.ForumTag{}, f.available...), discordgo.ForumTag{Name: name})
+	channel, err := f.session.ChannelEdit(f.channelID, &discordgo.ChannelEdit{AvailableTags: &tags})
+	if err != nil {
+		// Most likely missing permissions; don't fail every post on it.
+		f.create = false
+		return "", fmt.Errorf("failed to create forum tag %q, tag creation disabled: %w", name, err)
+	}
+	f.available = channel.AvailableTags
+
+	for _, tag := range f.available {
+		if tag.Name == name {
+			return tag.ID, nil
+		}
+	}
+	return "", nil
+}
+
+// containsWords reports whether the words of slug sub appear in slug s as
+// a run of whole words.
+func containsWords(s, sub string) bool {
+	words, subWords := strings.Split(s, "-"), strings.Split(sub, "-")
+	for i := 0; i+len(subWords) <= len(words); i++ {
+		if slices.Equal(words[i:i+len(subWords)], subWords) {
+			return true
+		}
+	}
+	return false
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -3,6 +3,7 @@ package discord
 import (
 	"bytes"
 	"cartero/internal/components"
+	"cartero/internal/config"
 	"cartero/internal/platforms"
 	"cartero/internal/types"
 	"cartero/internal/utils"
@@ -10,6 +11,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"strings"
 	"text/template"
 	"time"
@@ -23,9 +25,13 @@ type Target struct {
 	channelID   string
 	channelType string
 	template    *template.Template
+	forumTagMap map[string]string
+	createTags  bool
+	tags        *forumTags
+	logger      *slog.Logger
 }
 
-func New(name string, channelID, channelType string, registry *components.Registry) *Target {
+func New(name string, settings config.TargetSettings, registry *components.Registry, logger *slog.Logger) *Target {
 	platformCmp := registry.Get(components.PlatformComponentName).(*components.PlatformComponent)
 	templatePath := "templates/discord.tmpl"
 
@@ -36,10 +42,13 @@ func New(name string, channelID, channelType string, registry *components.Regist
 
 	return &Target{
 		name:        name,
-		channelID:   channelID,
-		channelType: channelType,
+		channelID:   settings.ChannelID,
+		channelType: settings.ChannelType,
 		template:    tmpl,
 		platform:    platformCmp.Discord(),
+		forumTagMap: settings.ForumTags,
+		createTags:  settings.CreateMissingTags,
+		logger:      logger,
 	}
 }
 
@@ -47,8 +56,14 @@ func (d *Target) Name() string {
 	return d.name
 }
 
+// Initialize loads a forum's available tags, which threads are tagged from.
 func (d *Target) Initialize(ctx context.Context) error {
-	return nil
+	if d.channelType != "forum" {
+		return nil
+	}
+
+	d.tags = newForumTags(d.platform.Session(), d.channelID, d.forumTagMap, d.createTags, d.logger)
+	return d.tags.load()
 }
 
 func (d *Target) Publish(ctx context.Context, item *types.Item) (*types.PublishResult, error) {
@@ -93,8 +108,11 @@ func (d *Target) createForumThread(item *types.Item) (string, error) {
 		return "", fmt.Errorf("failed to build embed: %w", err)
 	}
 
-	thread, err := d.platform.Session().ForumThreadStartEmbed(d.channelID, title, 1440, embed)
-
+	thread, err := d.platform.Session().ForumThreadStartComplex(d.channelID, &discordgo.ThreadStart{
+		Name:                title,
+		AutoArchiveDuration: 1440,
+		AppliedTags:         d.appliedTags(item),
+	}, &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}})
 	if err != nil {
 		return "", fmt.Errorf("failed to create forum thread: %w", err)
 	}
@@ -102,6 +120,13 @@ func (d *Target) createForumThread(item *types.Item) (string, error) {
 	return thread.ID, nil
 }
 
+func (d *Target) appliedTags(item *types.Item) []string {
+	if d.tags == nil {
+		return nil
+	}
+	return d.tags.For(item)
+}
+
 func (d *Target) sendMessage(item *types.Item) (string, error) {
 	embed, err := d.buildEmbed(item)
 	if err != nil {
@@ -135,6 +160,84 @@ func (d *Target) buildEmbed(item *types.Item) (*discordgo.MessageEmbed, error) {
 	return dgEmbed, nil
 }
 
+// Update re-renders the embed of a published post. Forum threads are also
+// renamed, since the thread title is the item title, and retagged.
+func (d *Target) Update(ctx context.Context, item *types.Item, published map[string]any) (*types.PublishResult, error) {
+	channelID, messageID, err := d.messageRef(published)
+	if err != nil {
+		return nil, err
+	}
+
+	embed, err := d.buildEmbed(item)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build embed: %w", err)
+	}
+
+	session := d.platform.Session()
+	if d.channelType == "forum" {
+		title := strutils.Truncate(item.GetTitle(), 100)
+		edit := &discordgo.ChannelEdit{Name: title}
+		if appliedTags := d.appliedTags(item); len(appliedTags) > 0 {
+			edit.AppliedTags = &appliedTags
+		}
+		if _, err := session.ChannelEdit(channelID, edit); err != nil {
+			return nil, fmt.Errorf("failed to rename forum thread: %w", err)
+		}
+	}
+
+	if _, err := session.ChannelMessageEditEmbed(channelID, messageID, embed); err != nil {
+		return nil, fmt.Errorf("failed to edit message: %w", err)
+	}
+
+	return &types.PublishResult{
+		Success: true,
+		Metadata: map[string]any{
+			"message_id": messageID,
+			"channel_id": d.channelID,
+		},
+	}, nil
+}
+
+// Delete removes a published post; for forums the whole thread is deleted.
+func (d *Target) Delete(ctx context.Context, item *types.Item, published map[string]any) error {
+	channelID, messageID, err := d.messageRef(published)
+	if err != nil {
+		return err
+	}
+
+	session := d.platform.Session()
+	if d.channelType == "forum" {
+		if _, err := session.ChannelDelete(channelID); err != nil {
+			return fmt.Errorf("failed to delete forum thread: %w", err)
+		}
+		return nil
+	}
+
+	if err := session.ChannelMessageDelete(channelID, messageID); err != nil {
+		return fmt.Errorf("failed to delete message: %w", err)
+	}
+	return nil
+}
+
+// messageRef resolves the channel and message a publish result points to.
+// A forum thread's starter message shares the thread's ID.
+func (d *Target) messageRef(published map[string]any) (channelID, messageID string, err error) {
+	messageID, _ = published["message_id"].(string)
+	if messageID == "" {
+		return "", "", fmt.Errorf("publish result has no message_id")
+	}
+
+	if d.channelType == "forum" {
+		return messageID, messageID, nil
+	}
+
+	channelID, _ = published["channel_id"].(string)
+	if channelID == "" {
+		channelID = d.channelID
+	}
+	return channelID, messageID, nil
+}
+
 func (d *Target) Shutdown(ctx context.Context) error {
 	return nil
 }
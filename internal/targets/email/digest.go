@@ -0,0 +1,80 @@
+package email
+
+import (
+	"cartero/internal/types"
+	"cmp"
+	"slices"
+	"time"
+)
+
+const (
+	groupByKeywords = "keywords"
+	groupBySource   = "source"
+
+	otherGroup = "Other"
+)
+
+// Digest is the data passed to the HTML and text templates.
+type Digest struct {
+	Subject string
+	Date    time.Time
+	Count   int
+	Groups  []Group
+}
+
+type Group struct {
+	Name  string
+	Items []*types.Item
+}
+
+// NewDigest groups items by matched keywords or by source. Items within a
+// group are ordered by score, and groups by their best item's score.
+func NewDigest(subject string, date time.Time, items []*types.Item, groupBy string) *Digest {
+	byName := make(map[string]*Group)
+	var groups []*Group
+	for _, item := range items {
+		name := groupName(item, groupBy)
+		g, ok := byName[name]
+		if !ok {
+			g = &Group{Name: name}
+			byName[name] = g
+			groups = append(groups, g)
+		}
+		g.Items = append(g.Items, item)
+	}
+
+	for _, g := range groups {
+		slices.SortStableFunc(g.Items, func(a, b *types.Item) int {
+			if c := cmp.Compare(b.GetScore(), a.GetScore()); c != 0 {
+				return c
+			}
+			return b.GetTimestamp().Compare(a.GetTimestamp())
+		})
+	}
+	slices.SortStableFunc(groups, func(a, b *Group) int {
+		if c := cmp.Compare(b.Items[0].GetScore(), a.Items[0].GetScore()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	digest := &Digest{Subject: subject, Date: date, Count: len(items)}
+	for _, g := range groups {
+		digest.Groups = append(digest.Groups, *g)
+	}
+	return digest
+}
+
+func groupName(item *types.Item, groupBy string) string {
+	var name string
+	switch groupBy {
+	case groupBySource:
+		name = item.GetSource()
+	default:
+		name = item.GetMatchedKeywords()
+	}
+	if name == "" {
+		return otherGroup
+	}
+	return name
+}
@@ -0,0 +1,144 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// buildMessage assembles a multipart/alternative message with the plain-text
+// part first, so clients that can render HTML pick the last one.
+func buildMessage(from *mail.Address, to []*mail.Address, subject, messageID string, date time.Time, text, html []byte) ([]byte, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+
+	for _, part := range []struct {
+		contentType string
+		content     []byte
+	}{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Type", part.contentType)
+		h.Set("Content-Transfer-Encoding", "quoted-printable")
+		pw, err := w.CreatePart(h)
+		if err != nil {
+			return nil, fmt.Errorf("email: create part: %w", err)
+		}
+		qp := quotedprintable.NewWriter(pw)
+		if _, err := qp.Write(part.content); err != nil {
+			return nil, fmt.Errorf("email: write part: %w", err)
+		}
+		if err := qp.Close(); err != nil {
+			return nil, fmt.Errorf("email: write part: %w", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("email: close multipart: %w", err)
+	}
+
+	recipients := make([]string, len(to))
+	for i, addr := range to {
+		recipients[i] = addr.String()
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from.String())
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(recipients, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Message-ID: %s\r\n", messageID)
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%q\r\n", w.Boundary())
+	fmt.Fprintf(&msg, "\r\n")
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
+
+func newMessageID(from *mail.Address) string {
+	domain := "localhost"
+	if at := strings.LastIndex(from.Address, "@"); at >= 0 {
+		domain = from.Address[at+1:]
+	}
+
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(b), domain)
+}
+
+// send delivers msg over SMTP. Unless insecure is set the server must offer
+// STARTTLS; credentials are only sent after the upgrade. net/smtp refuses
+// PLAIN auth over an unencrypted connection except to localhost, which is
+// what local test sinks need.
+func (t *Target) send(ctx context.Context, msg []byte) error {
+	addr := net.JoinHostPort(t.host, strconv.Itoa(t.port))
+
+	dialer := net.Dialer{Timeout: t.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("email: dial %s: %w", addr, err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(t.timeout))
+
+	c, err := smtp.NewClient(conn, t.host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("email: %w", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	if !t.insecure {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("email: %s does not support STARTTLS", addr)
+		}
+		if err := c.StartTLS(&tls.Config{ServerName: t.host}); err != nil {
+			return fmt.Errorf("email: starttls: %w", err)
+		}
+	}
+
+	if t.username != "" {
+		if err := c.Auth(smtp.PlainAuth("", t.username, t.password, t.host)); err != nil {
+			return fmt.Errorf("email: auth: %w", err)
+		}
+	}
+
+	if err := c.Mail(t.from.Address); err != nil {
+		return fmt.Errorf("email: MAIL FROM: %w", err)
+	}
+	for _, rcpt := range t.to {
+		if err := c.Rcpt(rcpt.Address); err != nil {
+			return fmt.Errorf("email: RCPT TO %s: %w", rcpt.Address, err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("email: DATA: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("email: write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("email: message rejected: %w", err)
+	}
+
+	// The digest was accepted once DATA completed; a failed QUIT doesn't
+	// change that.
+	_ = c.Quit()
+	return nil
+}
@@ -0,0 +1,338 @@
+package email
+
+import (
+	"bytes"
+	"cartero/internal/components"
+	"cartero/internal/config"
+	"cartero/internal/storage"
+	"cartero/internal/types"
+	"cartero/internal/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"log/slog"
+	"net/mail"
+	"sync"
+	"text/template"
+	"time"
+)
+
+const (
+	defaultPort    = 587
+	defaultSubject = "Cartero digest"
+	defaultSendAt  = "08:00"
+
+	// retryInterval is how long to wait before trying again after a digest
+	// failed to send, unless the next scheduled send comes first.
+	retryInterval = 15 * time.Minute
+)
+
+// Queue holds items between Publish and the next digest, and when the last
+// digest was sent in run-once mode.
+type Queue interface {
+	Add(ctx context.Context, name, id string, payload []byte) error
+	Has(ctx context.Context, name, id string) (bool, error)
+	All(ctx context.Context, name string) (map[string][]byte, error)
+	Remove(ctx context.Context, name string, ids ...string) error
+	LastSent(ctx context.Context, name string) (time.Time, error)
+	SetLastSent(ctx context.Context, name string, at time.Time) error
+}
+
+// Target collects items and mails them as one digest a day. Publish only
+// queues an item; it is marked published once the SMTP server accepts the
+// digest that contains it.
+type Target struct {
+	name     string
+	host     string
+	port     int
+	username string
+	password string
+	insecure bool
+	from     *mail.Address
+	to       []*mail.Address
+	subject  string
+	hour     int
+	minute   int
+	location *time.Location
+	groupBy  string
+	timeout  time.Duration
+	text     *template.Template
+	html     *htmltemplate.Template
+
+	queue      Queue
+	entryStore storage.EntryStore
+	logger     *slog.Logger
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func New(name string, settings config.TargetSettings, registry *components.Registry, queue Queue, logger *slog.Logger) (*Target, error) {
+	if settings.SMTPHost == "" {
+		return nil, fmt.Errorf("email: smtp_host is required")
+	}
+
+	from, err := mail.ParseAddress(settings.From)
+	if err != nil {
+		return nil, fmt.Errorf("email: invalid from address: %w", err)
+	}
+	if len(settings.To) == 0 {
+		return nil, fmt.Errorf("email: at least one recipient is required")
+	}
+	to := make([]*mail.Address, len(settings.To))
+	for i, addr := range settings.To {
+		if to[i], err = mail.ParseAddress(addr); err != nil {
+			return nil, fmt.Errorf("email: invalid recipient %q: %w", addr, err)
+		}
+	}
+
+	sendAt := settings.SendAt
+	if sendAt == "" {
+		sendAt = defaultSendAt
+	}
+	at, err := time.Parse("15:04", sendAt)
+	if err != nil {
+		return nil, fmt.Errorf("email: invalid send_at %q, expected HH:MM", sendAt)
+	}
+
+	location := time.Local
+	if settings.TimeZone != "" {
+		if location, err = time.LoadLocation(settings.TimeZone); err != nil {
+			return nil, fmt.Errorf("email: invalid time_zone: %w", err)
+		}
+	}
+
+	groupBy := settings.GroupBy
+	switch groupBy {
+	case "":
+		groupBy = groupByKeywords
+	case groupByKeywords, groupBySource:
+	default:
+		return nil, fmt.Errorf("email: group_by must be %q or %q", groupByKeywords, groupBySource)
+	}
+
+	textPath := settings.Template
+	if textPath == "" {
+		textPath = "templates/email.txt.tmpl"
+	}
+	text, err := utils.LoadTemplate(textPath)
+	if err != nil {
+		return nil, fmt.Errorf("email: %w", err)
+	}
+
+	htmlPath := settings.HTMLTemplate
+	if htmlPath == "" {
+		htmlPath = "templates/email.html.tmpl"
+	}
+	html := &utils.Template{}
+	if err := html.Load(htmlPath, true, nil); err != nil {
+		return nil, fmt.Errorf("email: %w", err)
+	}
+
+	port := settings.SMTPPort
+	if port == 0 {
+		port = defaultPort
+	}
+
+	subject := settings.Subject
+	if subject == "" {
+		subject = defaultSubject
+	}
+
+	store := registry.Get(components.StorageComponentName).(*components.StorageComponent).Store()
+
+	return &Target{
+		name:       name,
+		host:       settings.SMTPHost,
+		port:       port,
+		username:   settings.SMTPUsername,
+		password:   settings.SMTPPassword,
+		insecure:   settings.SMTPInsecure,
+		from:       from,
+		to:         to,
+		subject:    subject,
+		hour:       at.Hour(),
+		minute:     at.Minute(),
+		location:   location,
+		groupBy:    groupBy,
+		timeout:    config.ParseDuration(settings.Timeout, 30*time.Second),
+		text:       text,
+		html:       html.HTMLTemplate(),
+		queue:      queue,
+		entryStore: store.Entries(),
+		logger:     logger,
+	}, nil
+}
+
+func (t *Target) Name() string {
+	return t.name
+}
+
+// Initialize starts the schedule that sends the digest.
+func (t *Target) Initialize(ctx context.Context) error {
+	ctx, t.cancel = context.WithCancel(ctx)
+	t.done = make(chan struct{})
+	go t.run(ctx)
+	return nil
+}
+
+// Publish queues the item for the next digest.
+func (t *Target) Publish(ctx context.Context, item *types.Item) (*types.PublishResult, error) {
+	payload, err := json.Marshal(item.Clone())
+	if err != nil {
+		return nil, fmt.Errorf("email: encode item: %w", err)
+	}
+	if err := t.queue.Add(ctx, t.name, item.ID, payload); err != nil {
+		return &types.PublishResult{Success: false, Error: err}, fmt.Errorf("email: queue item: %w", err)
+	}
+
+	return &types.PublishResult{
+		Success:  true,
+		Metadata: map[string]any{"queued": true},
+	}, nil
+}
+
+func (t *Target) Queued(ctx context.Context, itemID string) bool {
+	queued, err := t.queue.Has(ctx, t.name, itemID)
+	return err == nil && queued
+}
+
+func (t *Target) Shutdown(ctx context.Context) error {
+	if t.cancel == nil {
+		return nil
+	}
+	t.cancel()
+	select {
+	case <-t.done:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+func (t *Target) run(ctx context.Context) {
+	defer close(t.done)
+
+	next := t.next(time.Now())
+	t.logger.Info("email: digest scheduled", "target", t.name, "at", next)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(next)):
+		}
+
+		now := time.Now()
+		next = t.next(now)
+		if err := t.Flush(ctx); err != nil {
+			t.logger.Error("email: failed to send digest", "target", t.name, "error", err)
+			if retry := now.Add(retryInterval); retry.Before(next) {
+				next = retry
+			}
+		}
+	}
+}
+
+// next returns the first scheduled send time after now.
+func (t *Target) next(now time.Time) time.Time {
+	local := now.In(t.location)
+	at := time.Date(local.Year(), local.Month(), local.Day(), t.hour, t.minute, 0, 0, t.location)
+	if !at.After(local) {
+		at = at.AddDate(0, 0, 1)
+	}
+	return at
+}
+
+// previous returns the last scheduled send time at or before now.
+func (t *Target) previous(now time.Time) time.Time {
+	return t.next(now).AddDate(0, 0, -1)
+}
+
+// FlushDue sends the digest if a scheduled send time passed since the last
+// one went out. Run-once mode calls it at the end of each run, as there is
+// no schedule running between runs.
+func (t *Target) FlushDue(ctx context.Context) error {
+	sent, err := t.queue.LastSent(ctx, t.name)
+	if err != nil {
+		return fmt.Errorf("email: read last send: %w", err)
+	}
+
+	now := time.Now()
+	if !sent.Before(t.previous(now)) {
+		return nil
+	}
+	if err := t.Flush(ctx); err != nil {
+		return err
+	}
+	return t.queue.SetLastSent(ctx, t.name, now)
+}
+
+// Flush sends everything queued so far as one digest and marks those items
+// published. Nothing is sent when the queue is empty. Items stay queued if
+// the server doesn't accept the message.
+func (t *Target) Flush(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	payloads, err := t.queue.All(ctx, t.name)
+	if err != nil {
+		return fmt.Errorf("email: read queue: %w", err)
+	}
+	if len(payloads) == 0 {
+		return nil
+	}
+
+	items := make([]*types.Item, 0, len(payloads))
+	ids := make([]string, 0, len(payloads))
+	for id, payload := range payloads {
+		var item types.Item
+		if err := json.Unmarshal(payload, &item); err != nil {
+			t.logger.Warn("email: dropping undecodable queued item", "target", t.name, "item_id", id, "error", err)
+			_ = t.queue.Remove(ctx, t.name, id)
+			continue
+		}
+		items = append(items, &item)
+		ids = append(ids, id)
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	now := time.Now().In(t.location)
+	digest := NewDigest(t.subject, now, items, t.groupBy)
+
+	var text bytes.Buffer
+	if err := t.text.Execute(&text, digest); err != nil {
+		return fmt.Errorf("email: text template execution error: %w", err)
+	}
+	var html bytes.Buffer
+	if err := t.html.Execute(&html, digest); err != nil {
+		return fmt.Errorf("email: html template execution error: %w", err)
+	}
+
+	subject := fmt.Sprintf("%s – %s", t.subject, now.Format("Mon, Jan 2"))
+	messageID := newMessageID(t.from)
+	msg, err := buildMessage(t.from, t.to, subject, messageID, now, text.Bytes(), html.Bytes())
+	if err != nil {
+		return err
+	}
+
+	if err := t.send(ctx, msg); err != nil {
+		return err
+	}
+	t.logger.Info("email: digest sent", "target", t.name, "items", len(items), "message_id", messageID)
+
+	result := map[string]any{
+		"message_id": messageID,
+		"sent_at":    now.UTC().Format(time.RFC3339),
+	}
+	for _, id := range ids {
+		if err := t.entryStore.MarkPublished(ctx, id, t.name, result); err != nil {
+			t.logger.Error("email: failed to mark item published", "target", t.name, "item_id", id, "error", err)
+		}
+	}
+
+	return t.queue.Remove(ctx, t.name, ids...)
+}
@@ -0,0 +1,305 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	htmltemplate "html/template"
+	"io"
+	"log/slog"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+	"time"
+
+	"cartero/internal/storage"
+	"cartero/internal/types"
+)
+
+// smtpSink is a minimal SMTP server without STARTTLS or auth that accepts
+// every message, or rejects them at the end of DATA when reject is set.
+type smtpSink struct {
+	ln     net.Listener
+	reject bool
+
+	mu       sync.Mutex
+	from     string
+	rcpts    []string
+	messages []string
+}
+
+func newSMTPSink(t *testing.T) *smtpSink {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &smtpSink{ln: ln}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpSink) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+// received returns the envelope and messages accepted so far.
+func (s *smtpSink) received() (string, []string, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.from, append([]string(nil), s.rcpts...), append([]string(nil), s.messages...)
+}
+
+func (s *smtpSink) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	tp := textproto.NewConn(conn)
+
+	_ = tp.PrintfLine("220 sink ready")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			_ = tp.PrintfLine("250 sink")
+		case "MAIL":
+			s.mu.Lock()
+			s.from = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+			s.rcpts = nil
+			s.mu.Unlock()
+			_ = tp.PrintfLine("250 OK")
+		case "RCPT":
+			s.mu.Lock()
+			s.rcpts = append(s.rcpts, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			s.mu.Unlock()
+			_ = tp.PrintfLine("250 OK")
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+			data, err := io.ReadAll(tp.DotReader())
+			if err != nil {
+				return
+			}
+			if s.reject {
+				_ = tp.PrintfLine("554 message rejected")
+				continue
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, string(data))
+			s.mu.Unlock()
+			_ = tp.PrintfLine("250 queued")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+type memQueue struct {
+	items map[string][]byte
+	sent  time.Time
+}
+
+func (q *memQueue) Add(_ context.Context, _, id string, payload []byte) error {
+	q.items[id] = payload
+	return nil
+}
+
+func (q *memQueue) Has(_ context.Context, _, id string) (bool, error) {
+	_, ok := q.items[id]
+	return ok, nil
+}
+
+func (q *memQueue) All(_ context.Context, _ string) (map[string][]byte, error) {
+	return q.items, nil
+}
+
+func (q *memQueue) Remove(_ context.Context, _ string, ids ...string) error {
+	for _, id := range ids {
+		delete(q.items, id)
+	}
+	return nil
+}
+
+func (q *memQueue) LastSent(_ context.Context, _ string) (time.Time, error) {
+	return q.sent, nil
+}
+
+func (q *memQueue) SetLastSent(_ context.Context, _ string, at time.Time) error {
+	q.sent = at
+	return nil
+}
+
+type entryStore struct {
+	storage.EntryStore
+	published []string
+}
+
+func (s *entryStore) MarkPublished(_ context.Context, itemID, _ string, _ map[string]any) error {
+	s.published = append(s.published, itemID)
+	return nil
+}
+
+func newTestTarget(sink *smtpSink) (*Target, *memQueue, *entryStore) {
+	queue := &memQueue{items: make(map[string][]byte)}
+	store := &entryStore{}
+
+	return &Target{
+		name:       "digest",
+		host:       "127.0.0.1",
+		port:       sink.port(),
+		insecure:   true,
+		from:       &mail.Address{Name: "Cartero", Address: "cartero@example.com"},
+		to:         []*mail.Address{{Address: "reader@example.com"}},
+		subject:    defaultSubject,
+		location:   time.UTC,
+		groupBy:    groupByKeywords,
+		timeout:    5 * time.Second,
+		text:       template.Must(template.New("text").Parse(`{{range .Groups}}{{.Name}}:{{range .Items}} {{.Title}}{{end}}{{end}}`)),
+		html:       htmltemplate.Must(htmltemplate.New("html").Parse(`<p>{{.Count}} items</p>`)),
+		queue:      queue,
+		entryStore: store,
+		logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}, queue, store
+}
+
+func publish(t *testing.T, target *Target, items ...*types.Item) {
+	for _, item := range items {
+		if _, err := target.Publish(context.Background(), item); err != nil {
+			t.Fatalf("Publish %s: %v", item.ID, err)
+		}
+	}
+}
+
+func TestFlushSendsDigest(t *testing.T) {
+	sink := newSMTPSink(t)
+	target, queue, store := newTestTarget(sink)
+
+	publish(t, target,
+		&types.Item{ID: "a", Title: "Go release", MatchedKeywords: "golang"},
+		&types.Item{ID: "b", Title: "Gopher news", MatchedKeywords: "golang"},
+	)
+	if !target.Queued(context.Background(), "a") {
+		t.Fatal("item not queued after Publish")
+	}
+
+	if err := target.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	from, rcpts, messages := sink.received()
+	if len(messages) != 1 {
+		t.Fatalf("sink received %d messages, want 1", len(messages))
+	}
+	if from != "cartero@example.com" || len(rcpts) != 1 || rcpts[0] != "reader@example.com" {
+		t.Errorf("envelope = %s -> %v", from, rcpts)
+	}
+
+	msg, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(messages[0])))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	if !strings.HasPrefix(msg.Header.Get("Content-Type"), "multipart/alternative") {
+		t.Errorf("Content-Type = %q", msg.Header.Get("Content-Type"))
+	}
+	body, _ := io.ReadAll(msg.Body)
+	if !strings.Contains(string(body), "Go release") || !strings.Contains(string(body), "2 items") {
+		t.Errorf("body is missing the digest:\n%s", body)
+	}
+
+	if len(store.published) != 2 {
+		t.Errorf("marked %d items published, want 2", len(store.published))
+	}
+	if len(queue.items) != 0 {
+		t.Errorf("%d items still queued after the digest was sent", len(queue.items))
+	}
+}
+
+func TestFlushEmptyQueueSendsNothing(t *testing.T) {
+	sink := newSMTPSink(t)
+	target, _, _ := newTestTarget(sink)
+
+	if err := target.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if _, _, messages := sink.received(); len(messages) != 0 {
+		t.Errorf("sink received %d messages for an empty queue", len(messages))
+	}
+}
+
+func TestFlushKeepsItemsWhenRejected(t *testing.T) {
+	sink := newSMTPSink(t)
+	sink.reject = true
+	target, queue, store := newTestTarget(sink)
+
+	publish(t, target, &types.Item{ID: "a", Title: "Go release"})
+
+	if err := target.Flush(context.Background()); err == nil {
+		t.Fatal("Flush succeeded although the server rejected the message")
+	}
+	if len(store.published) != 0 {
+		t.Errorf("marked %v published after a rejected digest", store.published)
+	}
+	if len(queue.items) != 1 {
+		t.Errorf("%d items queued, want the rejected one kept", len(queue.items))
+	}
+}
+
+func TestSendRequiresSTARTTLS(t *testing.T) {
+	sink := newSMTPSink(t)
+	target, _, _ := newTestTarget(sink)
+	target.insecure = false
+
+	err := target.send(context.Background(), []byte("Subject: test\r\n\r\nbody\r\n"))
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("send error = %v, want a missing STARTTLS error", err)
+	}
+	if _, _, messages := sink.received(); len(messages) != 0 {
+		t.Error("message sent over a connection that was not upgraded")
+	}
+}
+
+func TestFlushDueSendsOncePerSchedule(t *testing.T) {
+	sink := newSMTPSink(t)
+	target, queue, _ := newTestTarget(sink)
+	// The digest was due an hour ago and the last one went out a day before.
+	now := time.Now().In(target.location)
+	target.hour, target.minute = now.Add(-time.Hour).Hour(), now.Minute()
+	queue.sent = now.Add(-25 * time.Hour)
+
+	publish(t, target, &types.Item{ID: "a", Title: "Go release"})
+	if err := target.FlushDue(context.Background()); err != nil {
+		t.Fatalf("FlushDue: %v", err)
+	}
+	if _, _, messages := sink.received(); len(messages) != 1 {
+		t.Fatalf("sink received %d messages, want the due digest", len(messages))
+	}
+
+	// A later run before the next send time leaves new items queued.
+	publish(t, target, &types.Item{ID: "b", Title: "Gopher news"})
+	if err := target.FlushDue(context.Background()); err != nil {
+		t.Fatalf("FlushDue: %v", err)
+	}
+	if _, _, messages := sink.received(); len(messages) != 1 {
+		t.Errorf("sink received %d messages, want no second digest before the next send time", len(messages))
+	}
+	if !target.Queued(context.Background(), "b") {
+		t.Error("item published after the digest is no longer queued")
+	}
+}
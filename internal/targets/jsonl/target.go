@@ -0,0 +1,63 @@
+package jsonl
+
+import (
+	"cartero/internal/archive"
+	"cartero/internal/config"
+	"cartero/internal/types"
+	"context"
+	"fmt"
+	"time"
+)
+
+// Target archives every published item as one JSON line, for offline
+// analysis and for replaying through the jsonl source.
+type Target struct {
+	name             string
+	writer           *archive.Writer
+	includeEmbedding bool
+}
+
+func New(name string, settings config.TargetSettings) (*Target, error) {
+	if settings.Directory == "" {
+		return nil, fmt.Errorf("jsonl: directory is required")
+	}
+	if settings.MaxSizeMB < 0 {
+		return nil, fmt.Errorf("jsonl: max_size_mb must not be negative")
+	}
+
+	return &Target{
+		name: name,
+		writer: archive.NewWriter(archive.WriterOptions{
+			Directory: settings.Directory,
+			Prefix:    name,
+			MaxSize:   int64(settings.MaxSizeMB) << 20,
+			Daily:     settings.RotateDaily,
+			Gzip:      settings.Gzip,
+		}),
+		includeEmbedding: settings.IncludeEmbedding,
+	}, nil
+}
+
+func (t *Target) Name() string {
+	return t.name
+}
+
+func (t *Target) Initialize(ctx context.Context) error {
+	return nil
+}
+
+func (t *Target) Publish(ctx context.Context, item *types.Item) (*types.PublishResult, error) {
+	now := time.Now()
+	if err := t.writer.Write(archive.NewRecord(item, now, t.includeEmbedding), now); err != nil {
+		return nil, fmt.Errorf("jsonl: %w", err)
+	}
+
+	return &types.PublishResult{
+		Success:  true,
+		Metadata: map[string]any{"archived_at": now.UTC().Format(time.RFC3339)},
+	}, nil
+}
+
+func (t *Target) Shutdown(ctx context.Context) error {
+	return t.writer.Close()
+}
@@ -0,0 +1,121 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type indexEntry struct {
+	link      string
+	title     string
+	source    string
+	score     float64
+	published time.Time
+}
+
+// writeIndex regenerates the index note for the local day of when from the
+// notes on disk. Only files modified since that day started are read, which
+// keeps this cheap in large vaults.
+func (t *Target) writeIndex(when time.Time) error {
+	when = when.Local()
+	dayStart := time.Date(when.Year(), when.Month(), when.Day(), 0, 0, 0, 0, time.Local)
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	indexRel, err := t.resolvePath(t.index, dayStart)
+	if err != nil {
+		return err
+	}
+	indexRel += ".md"
+
+	var entries []indexEntry
+	err = filepath.WalkDir(t.directory, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != t.directory && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".md" {
+			return nil
+		}
+		if info, err := d.Info(); err != nil || info.ModTime().Before(dayStart) {
+			return nil
+		}
+
+		fm, err := readFrontMatter(path)
+		if err != nil || fm["id"] == nil {
+			return nil
+		}
+		s, _ := fm["published"].(string)
+		published, err := time.Parse(time.RFC3339, s)
+		if err != nil || published.Before(dayStart) || !published.Before(dayEnd) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(t.directory, path)
+		if err != nil {
+			return nil
+		}
+		title, _ := fm["title"].(string)
+		source, _ := fm["source"].(string)
+		score, _ := fm["score"].(float64)
+		entries = append(entries, indexEntry{
+			link:      filepath.ToSlash(strings.TrimSuffix(rel, ".md")),
+			title:     title,
+			source:    source,
+			score:     score,
+			published: published,
+		})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("markdown: scan vault: %w", err)
+	}
+
+	slices.SortFunc(entries, func(a, b indexEntry) int {
+		return a.published.Compare(b.published)
+	})
+
+	date := dayStart.Format("2006-01-02")
+
+	var buf bytes.Buffer
+	if err := writeFrontMatter(&buf, []field{
+		{"title", date},
+		{"date", date},
+		{"type", "index"},
+		{"count", len(entries)},
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintf(&buf, "\n# %s\n\n", date)
+	for _, e := range entries {
+		fmt.Fprintf(&buf, "- [[%s|%s]]", e.link, wikiText(e.title))
+		var details []string
+		if e.source != "" {
+			details = append(details, e.source)
+		}
+		if e.score != 0 {
+			details = append(details, "score "+strconv.FormatFloat(e.score, 'f', 2, 64))
+		}
+		if len(details) > 0 {
+			fmt.Fprintf(&buf, " (%s)", strings.Join(details, ", "))
+		}
+		buf.WriteString("\n")
+	}
+
+	return writeFile(filepath.Join(t.directory, indexRel), buf.Bytes())
+}
+
+// wikiText keeps a title from closing or splitting a wiki link.
+func wikiText(s string) string {
+	return strings.NewReplacer("|", "-", "[", "(", "]", ")").Replace(s)
+}
@@ -0,0 +1,129 @@
+package markdown
+
+import (
+	"bufio"
+	"bytes"
+	"cartero/internal/types"
+	"cartero/internal/utils"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const frontMatterDelimiter = "---"
+
+// Note is the data passed to the note body and filename templates.
+type Note struct {
+	Item      *types.Item
+	Article   *types.Article
+	Summary   string
+	Published time.Time
+	Tags      []string
+}
+
+func newNote(item *types.Item, published time.Time, extraTags []string) *Note {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, tag := range append([]string{item.GetMatchedKeywords(), item.GetSource()}, extraTags...) {
+		tag = utils.Slug(tag)
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	return &Note{
+		Item:      item,
+		Article:   item.GetArticle(),
+		Summary:   item.GetSummary(),
+		Published: published,
+		Tags:      tags,
+	}
+}
+
+// field is one front matter entry. Values are written as JSON, which is
+// valid YAML and saves us from quoting rules.
+type field struct {
+	key   string
+	value any
+}
+
+func (n *Note) frontMatter() []field {
+	return []field{
+		{"id", n.Item.ID},
+		{"title", n.Item.GetTitle()},
+		{"url", n.Item.GetLink().String()},
+		{"source", n.Item.GetSource()},
+		{"author", n.Item.GetAuthor()},
+		{"score", n.Item.GetScore()},
+		{"matched_keywords", n.Item.GetMatchedKeywords()},
+		{"published", n.Published.Format(time.RFC3339)},
+		{"tags", n.Tags},
+	}
+}
+
+func writeFrontMatter(w io.Writer, fields []field) error {
+	if _, err := fmt.Fprintln(w, frontMatterDelimiter); err != nil {
+		return err
+	}
+	for _, f := range fields {
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return fmt.Errorf("encode %s: %w", f.key, err)
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s\n", f.key, value); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, frontMatterDelimiter)
+	return err
+}
+
+// readFrontMatter reads the values written by writeFrontMatter from the top
+// of a note. Lines that aren't JSON values are skipped, so hand edits in
+// other YAML styles don't break reading.
+func readFrontMatter(path string) (map[string]any, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() || scanner.Text() != frontMatterDelimiter {
+		return nil, nil
+	}
+
+	values := make(map[string]any)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == frontMatterDelimiter {
+			break
+		}
+		key, raw, ok := strings.Cut(line, ": ")
+		if !ok {
+			continue
+		}
+		var value any
+		if err := json.Unmarshal([]byte(raw), &value); err == nil {
+			values[key] = value
+		}
+	}
+	return values, scanner.Err()
+}
+
+func (n *Note) render(tmpl *template.Template) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeFrontMatter(&buf, n.frontMatter()); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+	if err := tmpl.Execute(&buf, n); err != nil {
+		return nil, fmt.Errorf("template execution error: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,265 @@
+package markdown
+
+import (
+	"bytes"
+	"cartero/internal/config"
+	"cartero/internal/types"
+	"cartero/internal/utils"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+const (
+	defaultFilename      = `{{ .Published.Format "2006-01-02" }}-{{ slug .Item.Title }}`
+	defaultIndexFilename = `Daily/{{ .Format "2006-01-02" }}`
+
+	// maxCollisions bounds the numeric suffixes tried for a taken filename.
+	maxCollisions = 100
+)
+
+// Target writes one Markdown note with YAML front matter per item into a
+// directory, for vaults such as Obsidian, and keeps a daily index note
+// linking the notes published that day.
+type Target struct {
+	name      string
+	directory string
+	tags      []string
+	template  *template.Template
+	filename  *template.Template
+	index     *template.Template
+
+	mu sync.Mutex
+}
+
+func New(name string, settings config.TargetSettings) (*Target, error) {
+	if settings.Directory == "" {
+		return nil, fmt.Errorf("markdown: directory is required")
+	}
+
+	templatePath := settings.Template
+	if templatePath == "" {
+		templatePath = "templates/markdown.tmpl"
+	}
+	tmpl, err := utils.LoadTemplate(templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("markdown: %w", err)
+	}
+
+	filename := settings.Filename
+	if filename == "" {
+		filename = defaultFilename
+	}
+	filenameTmpl, err := parsePathTemplate("filename", filename)
+	if err != nil {
+		return nil, err
+	}
+
+	indexFilename := settings.IndexFilename
+	if indexFilename == "" {
+		indexFilename = defaultIndexFilename
+	}
+	indexTmpl, err := parsePathTemplate("index_filename", indexFilename)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Target{
+		name:      name,
+		directory: settings.Directory,
+		tags:      settings.Tags,
+		template:  tmpl,
+		filename:  filenameTmpl,
+		index:     indexTmpl,
+	}, nil
+}
+
+func parsePathTemplate(key, text string) (*template.Template, error) {
+	tmpl, err := template.New(key).Funcs(template.FuncMap{"slug": utils.Slug}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("markdown: invalid %s: %w", key, err)
+	}
+	return tmpl, nil
+}
+
+func (t *Target) Name() string {
+	return t.name
+}
+
+func (t *Target) Initialize(ctx context.Context) error {
+	if err := os.MkdirAll(t.directory, 0o755); err != nil {
+		return fmt.Errorf("markdown: create directory: %w", err)
+	}
+	return nil
+}
+
+func (t *Target) Publish(ctx context.Context, item *types.Item) (*types.PublishResult, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	note := newNote(item, time.Now(), t.tags)
+
+	base, err := t.resolvePath(t.filename, note)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := t.claim(base, item.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.write(rel, note); err != nil {
+		return nil, err
+	}
+	if err := t.writeIndex(note.Published); err != nil {
+		return nil, err
+	}
+
+	return &types.PublishResult{
+		Success:  true,
+		Metadata: map[string]any{"path": rel},
+	}, nil
+}
+
+// Update rewrites a note in place, keeping its original published time.
+func (t *Target) Update(ctx context.Context, item *types.Item, published map[string]any) (*types.PublishResult, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	rel, _ := published["path"].(string)
+	if rel == "" {
+		return nil, fmt.Errorf("markdown: publish result has no path")
+	}
+
+	when := time.Now()
+	if fm, err := readFrontMatter(filepath.Join(t.directory, rel)); err == nil {
+		if s, _ := fm["published"].(string); s != "" {
+			if parsed, err := time.Parse(time.RFC3339, s); err == nil {
+				when = parsed
+			}
+		}
+	}
+
+	note := newNote(item, when, t.tags)
+	if err := t.write(rel, note); err != nil {
+		return nil, err
+	}
+	if err := t.writeIndex(note.Published); err != nil {
+		return nil, err
+	}
+
+	return &types.PublishResult{
+		Success:  true,
+		Metadata: map[string]any{"path": rel},
+	}, nil
+}
+
+// Delete removes the note and drops it from its day's index.
+func (t *Target) Delete(ctx context.Context, item *types.Item, published map[string]any) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	rel, _ := published["path"].(string)
+	if rel == "" {
+		return fmt.Errorf("markdown: publish result has no path")
+	}
+
+	path := filepath.Join(t.directory, rel)
+	fm, _ := readFrontMatter(path)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("markdown: remove note: %w", err)
+	}
+
+	if s, _ := fm["published"].(string); s != "" {
+		if when, err := time.Parse(time.RFC3339, s); err == nil {
+			return t.writeIndex(when)
+		}
+	}
+	return nil
+}
+
+func (t *Target) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// resolvePath renders a path template and keeps the result inside the
+// vault directory.
+func (t *Target) resolvePath(tmpl *template.Template, data any) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("markdown: %s template execution error: %w", tmpl.Name(), err)
+	}
+
+	rel := filepath.Clean(filepath.FromSlash(strings.TrimSpace(buf.String())))
+	if rel == "." || rel == "" || filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("markdown: %s %q is outside the vault", tmpl.Name(), buf.String())
+	}
+	return strings.TrimSuffix(rel, ".md"), nil
+}
+
+// claim picks the note path for an item. A file that already belongs to the
+// same item is reused, so retries overwrite instead of duplicating; otherwise
+// a numeric suffix is added until the name is free.
+func (t *Target) claim(base, itemID string) (string, error) {
+	for n := 1; n <= maxCollisions; n++ {
+		rel := base + ".md"
+		if n > 1 {
+			rel = base + "-" + strconv.Itoa(n) + ".md"
+		}
+
+		fm, err := readFrontMatter(filepath.Join(t.directory, rel))
+		if os.IsNotExist(err) {
+			return rel, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("markdown: read %s: %w", rel, err)
+		}
+		if id, _ := fm["id"].(string); id == itemID {
+			return rel, nil
+		}
+	}
+	return "", fmt.Errorf("markdown: no free filename for %q", base)
+}
+
+func (t *Target) write(rel string, note *Note) error {
+	content, err := note.render(t.template)
+	if err != nil {
+		return fmt.Errorf("markdown: %w", err)
+	}
+	return writeFile(filepath.Join(t.directory, rel), content)
+}
+
+// writeFile replaces path atomically, so a syncing client never picks up a
+// half-written note.
+func writeFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("markdown: create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".cartero-*")
+	if err != nil {
+		return fmt.Errorf("markdown: create temp file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("markdown: write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("markdown: write %s: %w", path, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("markdown: write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("markdown: write %s: %w", path, err)
+	}
+	return nil
+}
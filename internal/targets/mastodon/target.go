@@ -0,0 +1,179 @@
+package mastodon
+
+import (
+	"bytes"
+	"cartero/internal/components"
+	"cartero/internal/config"
+	"cartero/internal/platforms"
+	"cartero/internal/types"
+	"cartero/internal/utils"
+	"context"
+	"fmt"
+	"net/http"
+	"text/template"
+	"time"
+)
+
+const maxMediaSize = 16 * 1024 * 1024
+
+type Target struct {
+	name        string
+	platform    *platforms.MastodonPlatform
+	visibility  string
+	language    string
+	spoilerText string
+	maxChars    int
+	template    *template.Template
+	httpClient  *http.Client
+}
+
+func New(name string, settings config.TargetSettings, registry *components.Registry) (*Target, error) {
+	platformCmp := registry.Get(components.PlatformComponentName).(*components.PlatformComponent)
+	if platformCmp.Mastodon() == nil {
+		return nil, fmt.Errorf("mastodon: platform is not enabled")
+	}
+
+	templatePath := settings.Template
+	if templatePath == "" {
+		templatePath = "templates/mastodon.tmpl"
+	}
+	tmpl, err := utils.LoadTemplate(templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("mastodon: %w", err)
+	}
+
+	return &Target{
+		name:        name,
+		platform:    platformCmp.Mastodon(),
+		visibility:  settings.Visibility,
+		language:    settings.Language,
+		spoilerText: settings.SpoilerText,
+		maxChars:    settings.MaxChars,
+		template:    tmpl,
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (t *Target) Name() string {
+	return t.name
+}
+
+func (t *Target) Initialize(ctx context.Context) error {
+	if t.maxChars == 0 {
+		t.maxChars = t.platform.MaxChars()
+	}
+	return nil
+}
+
+func (t *Target) Publish(ctx context.Context, item *types.Item) (*types.PublishResult, error) {
+	status, err := t.render(item)
+	if err != nil {
+		return nil, err
+	}
+
+	input := t.input(item, status)
+
+	mediaID := ""
+	if imageURL := item.GetImageURL(); imageURL != "" {
+		if id, err := t.uploadImage(ctx, imageURL, status.Alt); err == nil {
+			mediaID = id
+			input.MediaIDs = []string{id}
+		}
+	}
+
+	posted, err := t.platform.PostStatus(ctx, input, t.name+":"+item.ID)
+	if err != nil {
+		return utils.Failure(err), err
+	}
+
+	return &types.PublishResult{
+		Success: true,
+		Metadata: map[string]any{
+			"status_id": posted.ID,
+			"uri":       posted.URI,
+			"url":       posted.URL,
+			"media_id":  mediaID,
+		},
+	}, nil
+}
+
+// Update edits a published status in place, keeping its attachment.
+func (t *Target) Update(ctx context.Context, item *types.Item, published map[string]any) (*types.PublishResult, error) {
+	id, _ := published["status_id"].(string)
+	if id == "" {
+		return nil, fmt.Errorf("mastodon: publish result has no status_id")
+	}
+
+	status, err := t.render(item)
+	if err != nil {
+		return nil, err
+	}
+
+	input := t.input(item, status)
+	if mediaID, _ := published["media_id"].(string); mediaID != "" {
+		input.MediaIDs = []string{mediaID}
+	}
+
+	edited, err := t.platform.EditStatus(ctx, id, input)
+	if err != nil {
+		return utils.Failure(err), err
+	}
+
+	return &types.PublishResult{
+		Success: true,
+		Metadata: map[string]any{
+			"status_id": edited.ID,
+			"uri":       edited.URI,
+			"url":       edited.URL,
+		},
+	}, nil
+}
+
+func (t *Target) Delete(ctx context.Context, item *types.Item, published map[string]any) error {
+	id, _ := published["status_id"].(string)
+	if id == "" {
+		return fmt.Errorf("mastodon: publish result has no status_id")
+	}
+	return t.platform.DeleteStatus(ctx, id)
+}
+
+func (t *Target) Shutdown(ctx context.Context) error {
+	t.httpClient.CloseIdleConnections()
+	return nil
+}
+
+func (t *Target) render(item *types.Item) (*Status, error) {
+	var buf bytes.Buffer
+	if err := t.template.Execute(&buf, item); err != nil {
+		return nil, fmt.Errorf("mastodon: template execution error: %w", err)
+	}
+
+	var status Status
+	if err := status.TryFrom(buf.Bytes()); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (t *Target) input(item *types.Item, status *Status) platforms.MastodonStatusInput {
+	var keywords []string
+	if kw := item.GetMatchedKeywords(); kw != "" {
+		keywords = append(keywords, kw)
+	}
+
+	return platforms.MastodonStatusInput{
+		Status:      status.Into(t.maxChars, keywords),
+		Visibility:  t.visibility,
+		Language:    t.language,
+		SpoilerText: t.spoilerText,
+	}
+}
+
+func (t *Target) uploadImage(ctx context.Context, imageURL, alt string) (string, error) {
+	image, err := utils.FetchImage(ctx, t.httpClient, imageURL, maxMediaSize)
+	if err != nil {
+		return "", err
+	}
+
+	return t.platform.UploadMedia(ctx, image.Data, image.Filename, image.ContentType, alt)
+}
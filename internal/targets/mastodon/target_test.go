@@ -0,0 +1,78 @@
+package mastodon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"cartero/internal/config"
+	"cartero/internal/platforms"
+	"cartero/internal/types"
+	"cartero/internal/utils"
+)
+
+// newTestTarget returns a target posting to an instance that rejects
+// statuses over maxChars the way Mastodon counts them, and the statuses
+// it accepted.
+func newTestTarget(t *testing.T, maxChars int) (*Target, *[]string) {
+	var posted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var input platforms.MastodonStatusInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if n := Length(input.Status); n > maxChars {
+			http.Error(w, fmt.Sprintf(`{"error":"Validation failed: Text character limit of %d exceeded (%d)"}`, maxChars, n), http.StatusUnprocessableEntity)
+			return
+		}
+		posted = append(posted, input.Status)
+		_ = json.NewEncoder(w).Encode(platforms.MastodonStatus{ID: "1"})
+	}))
+	t.Cleanup(srv.Close)
+
+	platform, err := platforms.NewMastodonPlatform(&config.MastodonPlatformSettings{InstanceURL: srv.URL + "/", AccessToken: "token"})
+	if err != nil {
+		t.Fatalf("NewMastodonPlatform: %v", err)
+	}
+	tmpl, err := utils.LoadTemplate("../../../templates/mastodon.tmpl")
+	if err != nil {
+		t.Fatalf("LoadTemplate: %v", err)
+	}
+
+	return &Target{name: "mastodon", platform: platform, maxChars: maxChars, template: tmpl, httpClient: srv.Client()}, &posted
+}
+
+func TestPublishFillsCharacterLimit(t *testing.T) {
+	target, posted := newTestTarget(t, 500)
+
+	// Short links count as 23 characters each, more than they have.
+	summary := strings.Repeat("See http://go.dev and read on. ", 40)
+	link, _ := url.Parse("https://example.com/posts/a-rather-long-path-that-counts-as-twenty-three")
+	item := &types.Item{
+		ID:              "a",
+		Title:           "Go release",
+		URL:             link,
+		MatchedKeywords: "golang",
+		Metadata:        map[string]any{"summary": summary},
+	}
+
+	if _, err := target.Publish(context.Background(), item); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if len(*posted) != 1 {
+		t.Fatalf("posted %d statuses, want 1", len(*posted))
+	}
+	status := (*posted)[0]
+	if n := Length(status); n != 500 {
+		t.Errorf("status is %d characters, want exactly the limit:\n%s", n, status)
+	}
+	if !strings.Contains(status, "…\n\n"+link.String()) || !strings.HasSuffix(status, "#Golang") {
+		t.Errorf("status lost its link or hashtag:\n%s", status)
+	}
+}
@@ -0,0 +1,131 @@
+package mastodon
+
+import (
+	"cartero/internal/utils"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// urlLength is how many characters Mastodon counts for any link.
+const urlLength = 23
+
+var urlPattern = regexp.MustCompile(`https?://\S+`)
+
+type Status struct {
+	Text     string   `json:"text"`
+	URL      string   `json:"url"`
+	Comments string   `json:"comments,omitempty"`
+	Alt      string   `json:"alt,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+func (s *Status) TryFrom(templateOutput []byte) error {
+	if err := json.Unmarshal(templateOutput, s); err != nil {
+		return fmt.Errorf("mastodon: failed to unmarshal template output to Status: %w", err)
+	}
+	return nil
+}
+
+// Into renders the status text within maxChars. Links and hashtags are
+// kept; hashtags are dropped from the end if they alone would take more
+// than half the budget, and the text is truncated to fit the rest.
+func (s *Status) Into(maxChars int, extraTags []string) string {
+	var links []string
+	if s.URL != "" {
+		links = append(links, s.URL)
+	}
+	if s.Comments != "" && s.Comments != s.URL {
+		links = append(links, "Discussion: "+s.Comments)
+	}
+
+	tags := Hashtags(append(append([]string{}, s.Tags...), extraTags...))
+	for len(tags) > 0 && Length(compose("", links, tags)) > maxChars/2 {
+		tags = tags[:len(tags)-1]
+	}
+
+	text := strings.TrimSpace(s.Text)
+	budget := maxChars - Length(compose("", links, tags))
+	if len(links) > 0 || len(tags) > 0 {
+		budget -= 2
+	}
+	text = truncate(text, max(budget, 0))
+
+	return compose(text, links, tags)
+}
+
+func compose(text string, links, tags []string) string {
+	var parts []string
+	if text != "" {
+		parts = append(parts, text)
+	}
+	if len(links) > 0 {
+		parts = append(parts, strings.Join(links, "\n"))
+	}
+	if len(tags) > 0 {
+		parts = append(parts, strings.Join(tags, " "))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// Length counts a status the way Mastodon does: runes, with every link
+// counting as 23 characters.
+func Length(s string) int {
+	n := len([]rune(s))
+	for _, u := range urlPattern.FindAllString(s, -1) {
+		n += urlLength - len([]rune(u))
+	}
+	return n
+}
+
+// Hashtags turns keywords ("rust async, wasm") into unique hashtags.
+func Hashtags(keywords []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, kw := range keywords {
+		for _, part := range strings.Split(kw, ",") {
+			tag := utils.Hashtag(strings.TrimPrefix(strings.TrimSpace(part), "#"))
+			if tag == "" || seen[strings.ToLower(tag)] {
+				continue
+			}
+			seen[strings.ToLower(tag)] = true
+			out = append(out, "#"+tag)
+		}
+	}
+	return out
+}
+
+// truncate cuts s to n characters as Length counts them, ending with an
+// ellipsis. Links are kept whole or left out.
+func truncate(s string, n int) string {
+	if Length(s) <= n {
+		return s
+	}
+	if n <= 0 {
+		return ""
+	}
+
+	links := urlPattern.FindAllStringIndex(s, -1)
+	end, used := 0, 0
+	for i := 0; i < len(s); {
+		if len(links) > 0 && links[0][0] == i {
+			if used+urlLength > n-1 {
+				break
+			}
+			used += urlLength
+			i = links[0][1]
+			links = links[1:]
+		} else {
+			if used+1 > n-1 {
+				break
+			}
+			_, size := utf8.DecodeRuneInString(s[i:])
+			used++
+			i += size
+		}
+		end = i
+	}
+	return strings.TrimSpace(s[:end]) + "…"
+}
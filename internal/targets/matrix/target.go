@@ -0,0 +1,175 @@
+package matrix
+
+import (
+	"bytes"
+	"cartero/internal/components"
+	"cartero/internal/config"
+	"cartero/internal/platforms"
+	"cartero/internal/types"
+	"cartero/internal/utils"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const maxMediaSize = 16 * 1024 * 1024
+
+type Target struct {
+	name       string
+	roomID     string
+	platform   *platforms.MatrixPlatform
+	template   *template.Template
+	httpClient *http.Client
+}
+
+func New(name string, settings config.TargetSettings, registry *components.Registry) (*Target, error) {
+	platformCmp := registry.Get(components.PlatformComponentName).(*components.PlatformComponent)
+	if platformCmp.Matrix() == nil {
+		return nil, fmt.Errorf("matrix: platform is not enabled")
+	}
+	if settings.RoomID == "" {
+		return nil, fmt.Errorf("matrix: room_id is required")
+	}
+
+	templatePath := settings.Template
+	if templatePath == "" {
+		templatePath = "templates/matrix.tmpl"
+	}
+	tmpl, err := utils.LoadTemplate(templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("matrix: %w", err)
+	}
+
+	return &Target{
+		name:       name,
+		roomID:     settings.RoomID,
+		platform:   platformCmp.Matrix(),
+		template:   tmpl,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (t *Target) Name() string {
+	return t.name
+}
+
+func (t *Target) Initialize(ctx context.Context) error {
+	return nil
+}
+
+// Publish sends the rendered message, followed by the thumbnail as an
+// m.image event. Both use transaction IDs derived from the item, so a retry
+// after a lost response doesn't post twice.
+func (t *Target) Publish(ctx context.Context, item *types.Item) (*types.PublishResult, error) {
+	formatted, err := t.render(item)
+	if err != nil {
+		return nil, err
+	}
+
+	eventID, err := t.platform.SendMessage(ctx, t.roomID, txnID(t.name, item.ID), newTextContent(formatted))
+	if err != nil {
+		return utils.Failure(err), err
+	}
+
+	metadata := map[string]any{
+		"event_id": eventID,
+		"room_id":  t.roomID,
+	}
+
+	if imageURL := item.GetImageURL(); imageURL != "" {
+		if imageEventID, err := t.sendImage(ctx, item, imageURL); err == nil {
+			metadata["image_event_id"] = imageEventID
+		}
+	}
+
+	return &types.PublishResult{Success: true, Metadata: metadata}, nil
+}
+
+// Update edits the published message with an m.replace event.
+func (t *Target) Update(ctx context.Context, item *types.Item, published map[string]any) (*types.PublishResult, error) {
+	roomID, eventID, err := t.eventRef(published)
+	if err != nil {
+		return nil, err
+	}
+
+	formatted, err := t.render(item)
+	if err != nil {
+		return nil, err
+	}
+
+	content := newEditContent(eventID, newTextContent(formatted))
+	if _, err := t.platform.SendMessage(ctx, roomID, txnID(t.name, item.ID, "edit", formatted), content); err != nil {
+		return utils.Failure(err), err
+	}
+
+	return &types.PublishResult{
+		Success: true,
+		Metadata: map[string]any{
+			"event_id": eventID,
+			"room_id":  roomID,
+		},
+	}, nil
+}
+
+// Delete redacts the message and its thumbnail.
+func (t *Target) Delete(ctx context.Context, item *types.Item, published map[string]any) error {
+	roomID, eventID, err := t.eventRef(published)
+	if err != nil {
+		return err
+	}
+
+	if imageEventID, _ := published["image_event_id"].(string); imageEventID != "" {
+		if err := t.platform.Redact(ctx, roomID, imageEventID, txnID(t.name, item.ID, "redact", imageEventID), "Removed upstream"); err != nil {
+			return err
+		}
+	}
+	return t.platform.Redact(ctx, roomID, eventID, txnID(t.name, item.ID, "redact", eventID), "Removed upstream")
+}
+
+func (t *Target) Shutdown(ctx context.Context) error {
+	t.httpClient.CloseIdleConnections()
+	return nil
+}
+
+func (t *Target) render(item *types.Item) (string, error) {
+	var buf bytes.Buffer
+	if err := t.template.Execute(&buf, item); err != nil {
+		return "", fmt.Errorf("matrix: template execution error: %w", err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func (t *Target) eventRef(published map[string]any) (roomID, eventID string, err error) {
+	eventID, _ = published["event_id"].(string)
+	if eventID == "" {
+		return "", "", fmt.Errorf("matrix: publish result has no event_id")
+	}
+	roomID, _ = published["room_id"].(string)
+	if roomID == "" {
+		roomID = t.roomID
+	}
+	return roomID, eventID, nil
+}
+
+func (t *Target) sendImage(ctx context.Context, item *types.Item, imageURL string) (string, error) {
+	image, err := utils.FetchImage(ctx, t.httpClient, imageURL, maxMediaSize)
+	if err != nil {
+		return "", err
+	}
+
+	mxc, err := t.platform.Upload(ctx, image.Data, image.Filename, image.ContentType)
+	if err != nil {
+		return "", err
+	}
+
+	content := ImageContent{
+		MsgType: "m.image",
+		Body:    item.GetTitle(),
+		URL:     mxc,
+		Info:    ImageInfo{MimeType: image.ContentType, Size: len(image.Data)},
+	}
+	return t.platform.SendMessage(ctx, t.roomID, txnID(t.name, item.ID, "image"), content)
+}
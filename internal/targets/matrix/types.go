@@ -0,0 +1,97 @@
+package matrix
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+const formatHTML = "org.matrix.custom.html"
+
+var (
+	linkPattern  = regexp.MustCompile(`(?is)<a\s[^>]*href="([^"]*)"[^>]*>(.*?)</a>`)
+	breakPattern = regexp.MustCompile(`(?i)<br\s*/?>`)
+	htmlStripper = bluemonday.StrictPolicy()
+)
+
+// TextContent is an m.text message with an HTML formatted body.
+type TextContent struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format"`
+	FormattedBody string `json:"formatted_body"`
+}
+
+// ImageContent is an m.image message pointing into the media repository.
+type ImageContent struct {
+	MsgType string    `json:"msgtype"`
+	Body    string    `json:"body"`
+	URL     string    `json:"url"`
+	Info    ImageInfo `json:"info"`
+}
+
+type ImageInfo struct {
+	MimeType string `json:"mimetype,omitempty"`
+	Size     int    `json:"size,omitempty"`
+}
+
+// EditContent replaces an earlier message (m.replace). Body carries the
+// "* " prefix clients without edit support show.
+type EditContent struct {
+	TextContent
+	NewContent TextContent `json:"m.new_content"`
+	RelatesTo  RelatesTo   `json:"m.relates_to"`
+}
+
+type RelatesTo struct {
+	RelType string `json:"rel_type"`
+	EventID string `json:"event_id"`
+}
+
+func newTextContent(formatted string) TextContent {
+	return TextContent{
+		MsgType:       "m.text",
+		Body:          plainText(formatted),
+		Format:        formatHTML,
+		FormattedBody: formatted,
+	}
+}
+
+func newEditContent(eventID string, content TextContent) EditContent {
+	fallback := content
+	fallback.Body = "* " + content.Body
+	fallback.FormattedBody = "* " + content.FormattedBody
+
+	return EditContent{
+		TextContent: fallback,
+		NewContent:  content,
+		RelatesTo:   RelatesTo{RelType: "m.replace", EventID: eventID},
+	}
+}
+
+// plainText derives the body from the HTML the template rendered, keeping
+// link targets since they'd otherwise be lost.
+func plainText(formatted string) string {
+	text := linkPattern.ReplaceAllStringFunc(formatted, func(m string) string {
+		parts := linkPattern.FindStringSubmatch(m)
+		href, label := parts[1], parts[2]
+		if href == "" || strings.TrimSpace(label) == href {
+			return label
+		}
+		return label + " (" + href + ")"
+	})
+	text = breakPattern.ReplaceAllString(text, "\n")
+	text = html.UnescapeString(htmlStripper.Sanitize(text))
+	return strings.TrimSpace(text)
+}
+
+// txnID derives a transaction ID from the target and item, so a retried
+// send is deduplicated by the homeserver.
+func txnID(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return "cartero-" + hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,215 @@
+package push
+
+import (
+	"bytes"
+	"cartero/internal/config"
+	"cartero/internal/platforms"
+	"cartero/internal/types"
+	"cartero/internal/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	ServiceNtfy   = "ntfy"
+	ServiceGotify = "gotify"
+)
+
+// Target sends phone notifications through ntfy or Gotify. The priority
+// comes from the item's score band, so only top-ranked items make noise;
+// combine with a per-target min_score processor to drop the rest.
+type Target struct {
+	name     string
+	service  string
+	url      string
+	token    string
+	tags     []string
+	bands    []config.PriorityBand
+	template *template.Template
+	client   *http.Client
+}
+
+func New(name, service string, settings config.TargetSettings) (*Target, error) {
+	var bands []config.PriorityBand
+	switch service {
+	case ServiceNtfy:
+		bands = ntfyBands
+	case ServiceGotify:
+		bands = gotifyBands
+		if settings.Token == "" {
+			return nil, fmt.Errorf("gotify: token is required")
+		}
+	default:
+		return nil, fmt.Errorf("push: unsupported service: %s", service)
+	}
+	if settings.URL == "" {
+		return nil, fmt.Errorf("%s: url is required", service)
+	}
+	if len(settings.Priorities) > 0 {
+		bands = settings.Priorities
+	}
+
+	templatePath := settings.Template
+	if templatePath == "" {
+		templatePath = "templates/push.tmpl"
+	}
+	tmpl, err := utils.LoadTemplate(templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", service, err)
+	}
+
+	return &Target{
+		name:     name,
+		service:  service,
+		url:      strings.TrimRight(settings.URL, "/"),
+		token:    settings.Token,
+		tags:     settings.Tags,
+		bands:    sortBands(bands),
+		template: tmpl,
+		client:   &http.Client{Timeout: config.ParseDuration(settings.Timeout, 30*time.Second)},
+	}, nil
+}
+
+func (t *Target) Name() string {
+	return t.name
+}
+
+func (t *Target) Initialize(ctx context.Context) error {
+	return nil
+}
+
+func (t *Target) Publish(ctx context.Context, item *types.Item) (*types.PublishResult, error) {
+	var buf bytes.Buffer
+	if err := t.template.Execute(&buf, item); err != nil {
+		return nil, fmt.Errorf("%s: template execution error: %w", t.service, err)
+	}
+
+	var n Notification
+	if err := n.TryFrom(buf.Bytes()); err != nil {
+		return nil, err
+	}
+
+	priority := priorityFor(t.bands, item.GetScore())
+
+	var req *http.Request
+	var err error
+	if t.service == ServiceNtfy {
+		req, err = t.ntfyRequest(ctx, &n, priority)
+	} else {
+		req, err = t.gotifyRequest(ctx, &n, priority)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to create request: %w", t.service, err)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return &types.PublishResult{Success: false, Error: err}, fmt.Errorf("%s: request failed: %w", t.service, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		err := fmt.Errorf("%s: rate limited", t.service)
+		return &types.PublishResult{
+			Success: false,
+			Error:   err,
+			Metadata: map[string]any{
+				"retry_after": platforms.ParseRetryAfter(resp.Header.Get("Retry-After")),
+			},
+		}, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		err := fmt.Errorf("%s: unexpected status code %d: %s", t.service, resp.StatusCode, strings.TrimSpace(string(b)))
+		return &types.PublishResult{Success: false, Error: err}, err
+	}
+
+	var sent struct {
+		ID any `json:"id"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&sent)
+
+	return &types.PublishResult{
+		Success: true,
+		Metadata: map[string]any{
+			"message_id": sent.ID,
+			"priority":   priority,
+		},
+	}, nil
+}
+
+func (t *Target) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// ntfyRequest publishes to the topic URL with the message as body and the
+// rest as headers. Header values are RFC 2047 encoded, which ntfy decodes.
+func (t *Target) ntfyRequest(ctx context.Context, n *Notification, priority int) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, strings.NewReader(n.Message))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "text/markdown; charset=utf-8")
+	req.Header.Set("X-Markdown", "yes")
+	req.Header.Set("X-Title", mime.QEncoding.Encode("utf-8", n.Title))
+	req.Header.Set("X-Priority", strconv.Itoa(priority))
+	if len(t.tags) > 0 {
+		req.Header.Set("X-Tags", mime.QEncoding.Encode("utf-8", strings.Join(t.tags, ",")))
+	}
+	if n.Click != "" {
+		req.Header.Set("X-Click", n.Click)
+	}
+	if n.Image != "" {
+		req.Header.Set("X-Attach", n.Image)
+	}
+	if t.token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+	return req, nil
+}
+
+// gotifyRequest posts to /message with the app token. Extras make clients
+// render Markdown, open the click URL and show the image.
+func (t *Target) gotifyRequest(ctx context.Context, n *Notification, priority int) (*http.Request, error) {
+	notification := map[string]any{}
+	if n.Click != "" {
+		notification["click"] = map[string]string{"url": n.Click}
+	}
+	if n.Image != "" {
+		notification["bigImageUrl"] = n.Image
+	}
+
+	extras := map[string]any{
+		"client::display": map[string]string{"contentType": "text/markdown"},
+	}
+	if len(notification) > 0 {
+		extras["client::notification"] = notification
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"title":    n.Title,
+		"message":  n.Message,
+		"priority": priority,
+		"extras":   extras,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url+"/message", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gotify-Key", t.token)
+	return req, nil
+}
@@ -0,0 +1,64 @@
+package push
+
+import (
+	"cartero/internal/config"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// Notification is what templates/push.tmpl renders. Message may use
+// Markdown; both services are told to render it.
+type Notification struct {
+	Title   string `json:"title"`
+	Message string `json:"message"`
+	Click   string `json:"click,omitempty"`
+	Image   string `json:"image,omitempty"`
+}
+
+func (n *Notification) TryFrom(templateOutput []byte) error {
+	if err := json.Unmarshal(templateOutput, n); err != nil {
+		return fmt.Errorf("push: failed to unmarshal template output to Notification: %w", err)
+	}
+	return nil
+}
+
+// Default score bands. ntfy priorities run from 1 (min) to 5 (urgent) with
+// 3 as default; Gotify's from 0 to 10, where clients start making noise
+// around 4 and pop up from 8.
+var (
+	ntfyBands = []config.PriorityBand{
+		{MinScore: 0.9, Priority: 5},
+		{MinScore: 0.75, Priority: 4},
+		{MinScore: 0.5, Priority: 3},
+		{MinScore: 0, Priority: 2},
+	}
+	gotifyBands = []config.PriorityBand{
+		{MinScore: 0.9, Priority: 8},
+		{MinScore: 0.75, Priority: 6},
+		{MinScore: 0.5, Priority: 4},
+		{MinScore: 0, Priority: 2},
+	}
+)
+
+// sortBands orders bands from the highest min_score down, which is the
+// order priorityFor checks them in.
+func sortBands(bands []config.PriorityBand) []config.PriorityBand {
+	out := slices.Clone(bands)
+	slices.SortFunc(out, func(a, b config.PriorityBand) int {
+		return cmp.Compare(b.MinScore, a.MinScore)
+	})
+	return out
+}
+
+// priorityFor returns the priority of the first band score reaches, or the
+// lowest band's priority if it reaches none.
+func priorityFor(bands []config.PriorityBand, score float64) int {
+	for _, band := range bands {
+		if score >= band.MinScore {
+			return band.Priority
+		}
+	}
+	return bands[len(bands)-1].Priority
+}
@@ -0,0 +1,70 @@
+package readlater
+
+import (
+	"bytes"
+	"cartero/internal/platforms"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var errUnauthorized = errors.New("unauthorized")
+
+// client is the HTTP plumbing shared by the services.
+type client struct {
+	service string
+	baseURL string
+	http    *http.Client
+}
+
+// do sends req and decodes a JSON response into out, when out is non-nil.
+// The response headers are returned for APIs that answer with a location.
+func (c *client) do(req *http.Request, out any) (http.Header, error) {
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: request failed: %w", c.service, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return nil, &platforms.RateLimitError{Platform: c.service, RetryAfter: platforms.ParseRetryAfter(resp.Header.Get("Retry-After"))}
+	case resp.StatusCode == http.StatusUnauthorized:
+		return nil, fmt.Errorf("%s: %s %s: %w", c.service, req.Method, req.URL.Path, errUnauthorized)
+	case resp.StatusCode >= 300:
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("%s: %s %s returned status %d: %s", c.service, req.Method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return nil, fmt.Errorf("%s: decode %s response: %w", c.service, req.URL.Path, err)
+		}
+	}
+	return resp.Header, nil
+}
+
+func (c *client) newRequest(ctx context.Context, method, path string, body io.Reader, contentType string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to create request: %w", c.service, err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return req, nil
+}
+
+func (c *client) jsonRequest(ctx context.Context, method, path string, body any) (*http.Request, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to encode request: %w", c.service, err)
+	}
+	return c.newRequest(ctx, method, path, bytes.NewReader(payload), "application/json")
+}
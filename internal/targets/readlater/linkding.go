@@ -0,0 +1,72 @@
+package readlater
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// linkding talks to the Linkding REST API with an API token.
+type linkding struct {
+	client
+	token string
+}
+
+func (l *linkding) authorize(req *http.Request) {
+	req.Header.Set("Authorization", "Token "+l.token)
+}
+
+func (l *linkding) find(ctx context.Context, link string) (string, error) {
+	req, err := l.newRequest(ctx, http.MethodGet, "/api/bookmarks/check/?"+url.Values{"url": {link}}.Encode(), nil, "")
+	if err != nil {
+		return "", err
+	}
+	l.authorize(req)
+
+	var resp struct {
+		Bookmark *struct {
+			ID int64 `json:"id"`
+		} `json:"bookmark"`
+	}
+	if _, err := l.do(req, &resp); err != nil {
+		return "", err
+	}
+	if resp.Bookmark == nil {
+		return "", nil
+	}
+	return strconv.FormatInt(resp.Bookmark.ID, 10), nil
+}
+
+func (l *linkding) save(ctx context.Context, b *Bookmark) (string, error) {
+	req, err := l.jsonRequest(ctx, http.MethodPost, "/api/bookmarks/", map[string]any{
+		"url":         b.URL,
+		"title":       b.Title,
+		"description": b.Description,
+		"tag_names":   b.Tags,
+		"unread":      true,
+	})
+	if err != nil {
+		return "", err
+	}
+	l.authorize(req)
+
+	var resp struct {
+		ID int64 `json:"id"`
+	}
+	if _, err := l.do(req, &resp); err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(resp.ID, 10), nil
+}
+
+func (l *linkding) remove(ctx context.Context, id string) error {
+	req, err := l.newRequest(ctx, http.MethodDelete, "/api/bookmarks/"+url.PathEscape(id)+"/", nil, "")
+	if err != nil {
+		return err
+	}
+	l.authorize(req)
+
+	_, err = l.do(req, nil)
+	return err
+}
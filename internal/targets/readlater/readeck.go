@@ -0,0 +1,58 @@
+package readlater
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// readeck talks to the Readeck API with an API token. Readeck cannot look a
+// bookmark up by URL, so duplicates are only caught by the link index.
+type readeck struct {
+	client
+	token string
+}
+
+func (r *readeck) authorize(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+r.token)
+}
+
+func (r *readeck) find(ctx context.Context, link string) (string, error) {
+	return "", nil
+}
+
+// save creates a bookmark. Readeck extracts the article in the background
+// and answers with its ID in the Bookmark-Id header.
+func (r *readeck) save(ctx context.Context, b *Bookmark) (string, error) {
+	req, err := r.jsonRequest(ctx, http.MethodPost, "/api/bookmarks", map[string]any{
+		"url":    b.URL,
+		"title":  b.Title,
+		"labels": b.Tags,
+	})
+	if err != nil {
+		return "", err
+	}
+	r.authorize(req)
+
+	header, err := r.do(req, nil)
+	if err != nil {
+		return "", err
+	}
+	id := header.Get("Bookmark-Id")
+	if id == "" {
+		return "", fmt.Errorf("readeck: response has no Bookmark-Id")
+	}
+	return id, nil
+}
+
+func (r *readeck) remove(ctx context.Context, id string) error {
+	req, err := r.newRequest(ctx, http.MethodDelete, "/api/bookmarks/"+url.PathEscape(id), nil, "")
+	if err != nil {
+		return err
+	}
+	r.authorize(req)
+
+	_, err = r.do(req, nil)
+	return err
+}
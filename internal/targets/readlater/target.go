@@ -0,0 +1,193 @@
+package readlater
+
+import (
+	"cartero/internal/config"
+	"cartero/internal/types"
+	"cartero/internal/utils"
+	"cartero/internal/utils/hash"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	ServiceWallabag = "wallabag"
+	ServiceLinkding = "linkding"
+	ServiceReadeck  = "readeck"
+)
+
+// Bookmark is what gets saved for an item.
+type Bookmark struct {
+	URL         string
+	Title       string
+	Description string
+	Tags        []string
+}
+
+type service interface {
+	// find returns the ID of an existing bookmark for link, or "".
+	find(ctx context.Context, link string) (string, error)
+	save(ctx context.Context, b *Bookmark) (string, error)
+	remove(ctx context.Context, id string) error
+}
+
+// Index remembers the remote ID saved for each URL hash, per target.
+type Index interface {
+	Get(ctx context.Context, name, hash string) (string, error)
+	Set(ctx context.Context, name, hash, id string) error
+	Remove(ctx context.Context, name, hash string) error
+}
+
+// Target saves items into a self-hosted read-later or bookmark service.
+// Saving is idempotent on the URL: the same link arriving from two sources,
+// or a retried publish, reuses the bookmark that already exists.
+type Target struct {
+	name    string
+	service string
+	api     service
+	index   Index
+	tags    []string
+}
+
+func New(name, svc string, settings config.TargetSettings, index Index) (*Target, error) {
+	if settings.URL == "" {
+		return nil, fmt.Errorf("%s: url is required", svc)
+	}
+
+	c := client{
+		service: svc,
+		baseURL: strings.TrimRight(settings.URL, "/"),
+		http:    &http.Client{Timeout: config.ParseDuration(settings.Timeout, 30*time.Second)},
+	}
+
+	var api service
+	switch svc {
+	case ServiceWallabag:
+		if settings.ClientID == "" || settings.ClientSecret == "" || settings.Username == "" || settings.Password == "" {
+			return nil, fmt.Errorf("wallabag: client_id, client_secret, username and password are required")
+		}
+		api = &wallabag{
+			client:       c,
+			clientID:     settings.ClientID,
+			clientSecret: settings.ClientSecret,
+			username:     settings.Username,
+			password:     settings.Password,
+		}
+	case ServiceLinkding, ServiceReadeck:
+		if settings.Token == "" {
+			return nil, fmt.Errorf("%s: token is required", svc)
+		}
+		if svc == ServiceLinkding {
+			api = &linkding{client: c, token: settings.Token}
+		} else {
+			api = &readeck{client: c, token: settings.Token}
+		}
+	default:
+		return nil, fmt.Errorf("readlater: unsupported service: %s", svc)
+	}
+
+	return &Target{
+		name:    name,
+		service: svc,
+		api:     api,
+		index:   index,
+		tags:    settings.Tags,
+	}, nil
+}
+
+func (t *Target) Name() string {
+	return t.name
+}
+
+func (t *Target) Initialize(ctx context.Context) error {
+	return nil
+}
+
+func (t *Target) Publish(ctx context.Context, item *types.Item) (*types.PublishResult, error) {
+	link := item.GetLink()
+	if link.String() == "" {
+		return nil, fmt.Errorf("%s: item has no link", t.service)
+	}
+	urlHash := hash.HashURL(link)
+
+	id, err := t.index.Get(ctx, t.name, urlHash)
+	if err != nil {
+		return nil, fmt.Errorf("%s: link index: %w", t.service, err)
+	}
+	existing := id != ""
+
+	if !existing {
+		if id, err = t.api.find(ctx, link.String()); err != nil {
+			return utils.Failure(err), err
+		}
+		existing = id != ""
+	}
+	if !existing {
+		if id, err = t.api.save(ctx, t.bookmark(item)); err != nil {
+			return utils.Failure(err), err
+		}
+	}
+
+	// The bookmark exists remotely now; a lost index write only costs a
+	// lookup next time.
+	_ = t.index.Set(ctx, t.name, urlHash, id)
+
+	return &types.PublishResult{
+		Success: true,
+		Metadata: map[string]any{
+			"id":       id,
+			"url_hash": urlHash,
+			"existing": existing,
+		},
+	}, nil
+}
+
+// Delete removes the bookmark, unless it was already there before this
+// target published the item.
+func (t *Target) Delete(ctx context.Context, item *types.Item, published map[string]any) error {
+	id, _ := published["id"].(string)
+	if id == "" {
+		return fmt.Errorf("%s: publish result has no id", t.service)
+	}
+	if existing, _ := published["existing"].(bool); existing {
+		return nil
+	}
+
+	if err := t.api.remove(ctx, id); err != nil {
+		return err
+	}
+	if urlHash, _ := published["url_hash"].(string); urlHash != "" {
+		_ = t.index.Remove(ctx, t.name, urlHash)
+	}
+	return nil
+}
+
+func (t *Target) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func (t *Target) bookmark(item *types.Item) *Bookmark {
+	description := item.GetDescription()
+	if description == "" {
+		description = item.GetSummary()
+	}
+
+	var tags []string
+	seen := make(map[string]bool)
+	for _, tag := range append([]string{item.GetMatchedKeywords(), item.GetSource()}, t.tags...) {
+		tag = utils.Slug(tag)
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	return &Bookmark{
+		URL:         item.GetLink().String(),
+		Title:       item.GetTitle(),
+		Description: description,
+		Tags:        tags,
+	}
+}
@@ -0,0 +1,409 @@
+package readlater
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+
+	"cartero/internal/config"
+	"cartero/internal/platforms"
+	"cartero/internal/types"
+)
+
+type memIndex struct {
+	mu  sync.Mutex
+	ids map[string]string
+}
+
+func newMemIndex() *memIndex {
+	return &memIndex{ids: make(map[string]string)}
+}
+
+func (m *memIndex) Get(_ context.Context, name, hash string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ids[name+"/"+hash], nil
+}
+
+func (m *memIndex) Set(_ context.Context, name, hash, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ids[name+"/"+hash] = id
+	return nil
+}
+
+func (m *memIndex) Remove(_ context.Context, name, hash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.ids, name+"/"+hash)
+	return nil
+}
+
+// bookmarks is the state shared by the fake services: saved URLs by ID.
+type bookmarks struct {
+	mu     sync.Mutex
+	byID   map[string]map[string]any
+	nextID int
+}
+
+func newBookmarks() *bookmarks {
+	return &bookmarks{byID: make(map[string]map[string]any)}
+}
+
+func (b *bookmarks) add(fields map[string]any) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	id := strconv.Itoa(b.nextID)
+	b.byID[id] = fields
+	return id
+}
+
+func (b *bookmarks) find(link string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for id, fields := range b.byID {
+		if fields["url"] == link {
+			return id
+		}
+	}
+	return ""
+}
+
+func (b *bookmarks) remove(id string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, ok := b.byID[id]
+	delete(b.byID, id)
+	return ok
+}
+
+func (b *bookmarks) len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.byID)
+}
+
+func testItem() *types.Item {
+	return &types.Item{
+		ID:              "item-1",
+		Title:           "Go 1.24 released",
+		URL:             &url.URL{Scheme: "https", Host: "go.dev", Path: "/blog/go1.24"},
+		MatchedKeywords: "Go Releases",
+		Metadata:        map[string]any{"summary": "What's new in Go 1.24."},
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// fakeWallabag issues short tokens through the OAuth password and refresh
+// grants and revokes the current one when expire is called.
+type fakeWallabag struct {
+	*bookmarks
+
+	mu       sync.Mutex
+	token    int
+	valid    string
+	grants   []string
+	requests int
+}
+
+func (f *fakeWallabag) expire() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.valid = ""
+}
+
+func (f *fakeWallabag) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /oauth/v2/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.PostForm.Get("client_id") != "client" || r.PostForm.Get("client_secret") != "secret" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_client"})
+			return
+		}
+		grant := r.PostForm.Get("grant_type")
+		if grant == "password" && (r.PostForm.Get("username") != "reader" || r.PostForm.Get("password") != "hunter2") {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		f.mu.Lock()
+		f.token++
+		f.valid = "token-" + strconv.Itoa(f.token)
+		f.grants = append(f.grants, grant)
+		token := f.valid
+		f.mu.Unlock()
+
+		writeJSON(w, http.StatusOK, map[string]any{"access_token": token, "refresh_token": "refresh", "expires_in": 3600})
+	})
+	mux.HandleFunc("GET /api/entries/exists.json", func(w http.ResponseWriter, r *http.Request) {
+		if id := f.find(r.URL.Query().Get("url")); id != "" {
+			n, _ := strconv.Atoi(id)
+			writeJSON(w, http.StatusOK, map[string]any{"exists": n})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"exists": false})
+	})
+	mux.HandleFunc("POST /api/entries.json", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		id := f.add(map[string]any{"url": r.PostForm.Get("url"), "title": r.PostForm.Get("title"), "tags": r.PostForm.Get("tags")})
+		n, _ := strconv.Atoi(id)
+		writeJSON(w, http.StatusOK, map[string]any{"id": n})
+	})
+	mux.HandleFunc("DELETE /api/entries/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		if !f.remove(id[:len(id)-len(".json")]) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{})
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oauth/v2/token" {
+			f.mu.Lock()
+			f.requests++
+			authorized := f.valid != "" && r.Header.Get("Authorization") == "Bearer "+f.valid
+			f.mu.Unlock()
+			if !authorized {
+				writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_grant"})
+				return
+			}
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func newWallabag(t *testing.T) (*fakeWallabag, *Target) {
+	f := &fakeWallabag{bookmarks: newBookmarks()}
+	srv := httptest.NewServer(f.handler())
+	t.Cleanup(srv.Close)
+
+	target, err := New("wallabag", ServiceWallabag, config.TargetSettings{
+		CommonTargetSettings:    config.CommonTargetSettings{URL: srv.URL + "/", Tags: []string{"cartero"}},
+		ReadLaterTargetSettings: config.ReadLaterTargetSettings{ClientID: "client", ClientSecret: "secret", Username: "reader", Password: "hunter2"},
+	}, newMemIndex())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return f, target
+}
+
+func TestWallabagPublishAndDelete(t *testing.T) {
+	f, target := newWallabag(t)
+	ctx := context.Background()
+	item := testItem()
+
+	result, err := target.Publish(ctx, item)
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if result.Metadata["existing"] != false {
+		t.Errorf("existing = %v for a new entry", result.Metadata["existing"])
+	}
+
+	saved := f.byID[result.Metadata["id"].(string)]
+	if saved["url"] != "https://go.dev/blog/go1.24" || saved["tags"] != "go-releases,cartero" {
+		t.Errorf("saved entry = %v", saved)
+	}
+	if len(f.grants) != 1 || f.grants[0] != "password" {
+		t.Errorf("grants = %v, want one password grant", f.grants)
+	}
+
+	if err := target.Delete(ctx, item, result.Metadata); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if f.len() != 0 {
+		t.Errorf("%d entries left after Delete", f.len())
+	}
+}
+
+func TestWallabagRenewsRevokedToken(t *testing.T) {
+	f, target := newWallabag(t)
+	ctx := context.Background()
+
+	if _, err := target.Publish(ctx, testItem()); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	f.expire()
+
+	other := testItem()
+	other.URL = &url.URL{Scheme: "https", Host: "go.dev", Path: "/blog/loopvar"}
+	if _, err := target.Publish(ctx, other); err != nil {
+		t.Fatalf("Publish after the token was revoked: %v", err)
+	}
+
+	if len(f.grants) != 2 || f.grants[1] != "refresh_token" {
+		t.Errorf("grants = %v, want the token refreshed", f.grants)
+	}
+	if f.len() != 2 {
+		t.Errorf("%d entries saved, want 2", f.len())
+	}
+}
+
+func TestWallabagKeepsExistingEntry(t *testing.T) {
+	f, target := newWallabag(t)
+	ctx := context.Background()
+	id := f.add(map[string]any{"url": "https://go.dev/blog/go1.24"})
+
+	result, err := target.Publish(ctx, testItem())
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if result.Metadata["id"] != id || result.Metadata["existing"] != true {
+		t.Fatalf("result = %v, want the existing entry %s", result.Metadata, id)
+	}
+
+	if err := target.Delete(ctx, testItem(), result.Metadata); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if f.len() != 1 {
+		t.Error("Delete removed an entry the target didn't create")
+	}
+}
+
+func newLinkding(t *testing.T) (*bookmarks, *Target) {
+	b := newBookmarks()
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/bookmarks/check/", func(w http.ResponseWriter, r *http.Request) {
+		if id := b.find(r.URL.Query().Get("url")); id != "" {
+			n, _ := strconv.Atoi(id)
+			writeJSON(w, http.StatusOK, map[string]any{"bookmark": map[string]any{"id": n}})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"bookmark": nil})
+	})
+	mux.HandleFunc("POST /api/bookmarks/", func(w http.ResponseWriter, r *http.Request) {
+		var fields map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&fields)
+		n, _ := strconv.Atoi(b.add(fields))
+		writeJSON(w, http.StatusCreated, map[string]any{"id": n})
+	})
+	mux.HandleFunc("DELETE /api/bookmarks/{id}/", func(w http.ResponseWriter, r *http.Request) {
+		if !b.remove(r.PathValue("id")) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"detail": "Not found."})
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Token linkding-token" {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"detail": "Invalid token."})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	target, err := New("linkding", ServiceLinkding, config.TargetSettings{
+		CommonTargetSettings: config.CommonTargetSettings{URL: srv.URL, Token: "linkding-token"},
+	}, newMemIndex())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return b, target
+}
+
+func TestLinkdingPublishAndDelete(t *testing.T) {
+	b, target := newLinkding(t)
+	ctx := context.Background()
+	item := testItem()
+
+	result, err := target.Publish(ctx, item)
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	saved := b.byID[result.Metadata["id"].(string)]
+	if saved["description"] != "What's new in Go 1.24." || saved["unread"] != true {
+		t.Errorf("saved bookmark = %v, want the summary as description and unread", saved)
+	}
+
+	// A retry finds the link in the index and doesn't save it again.
+	retry, err := target.Publish(ctx, item)
+	if err != nil {
+		t.Fatalf("Publish retry: %v", err)
+	}
+	if retry.Metadata["id"] != result.Metadata["id"] || b.len() != 1 {
+		t.Errorf("retry saved %v, want the bookmark reused", retry.Metadata)
+	}
+
+	if err := target.Delete(ctx, item, result.Metadata); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if b.len() != 0 {
+		t.Errorf("%d bookmarks left after Delete", b.len())
+	}
+}
+
+func newReadeck(t *testing.T, handler http.HandlerFunc) *Target {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	target, err := New("readeck", ServiceReadeck, config.TargetSettings{
+		CommonTargetSettings: config.CommonTargetSettings{URL: srv.URL, Token: "readeck-token"},
+	}, newMemIndex())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return target
+}
+
+func TestReadeckPublishReadsBookmarkID(t *testing.T) {
+	var got map[string]any
+	target := newReadeck(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/bookmarks" || r.Header.Get("Authorization") != "Bearer readeck-token" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Bookmark-Id", "xyz789")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = io.WriteString(w, `{"status":202,"message":"Link submitted"}`)
+	})
+
+	result, err := target.Publish(context.Background(), testItem())
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if result.Metadata["id"] != "xyz789" {
+		t.Errorf("id = %v, want the Bookmark-Id header", result.Metadata["id"])
+	}
+	if got["url"] != "https://go.dev/blog/go1.24" || got["title"] != "Go 1.24 released" {
+		t.Errorf("request = %v", got)
+	}
+}
+
+func TestReadeckRateLimit(t *testing.T) {
+	target := newReadeck(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "42")
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	})
+
+	result, err := target.Publish(context.Background(), testItem())
+
+	var rateLimit *platforms.RateLimitError
+	if !errors.As(err, &rateLimit) || rateLimit.RetryAfter != 42 {
+		t.Fatalf("error = %v, want a RateLimitError after 42s", err)
+	}
+	if result == nil || result.Success {
+		t.Fatalf("result = %+v, want a failed result", result)
+	}
+	if result.Metadata["retry_after"] != 42.0 {
+		t.Errorf("retry_after = %v, want it passed to the retry queue", result.Metadata["retry_after"])
+	}
+}
@@ -0,0 +1,165 @@
+package readlater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenSlack renews the access token this long before it expires.
+const tokenSlack = time.Minute
+
+// wallabag talks to the Wallabag v2 API. Tokens come from the OAuth password
+// grant and are renewed with the refresh token, falling back to the password
+// grant when the refresh token is rejected as well.
+type wallabag struct {
+	client
+	clientID     string
+	clientSecret string
+	username     string
+	password     string
+
+	mu           sync.Mutex
+	accessToken  string
+	refreshToken string
+	expiresAt    time.Time
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+func (w *wallabag) token(ctx context.Context) (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.accessToken != "" && time.Now().Add(tokenSlack).Before(w.expiresAt) {
+		return w.accessToken, nil
+	}
+
+	if w.refreshToken != "" {
+		err := w.grant(ctx, url.Values{
+			"grant_type":    {"refresh_token"},
+			"refresh_token": {w.refreshToken},
+		})
+		if err == nil {
+			return w.accessToken, nil
+		}
+		w.refreshToken = ""
+	}
+
+	if err := w.grant(ctx, url.Values{
+		"grant_type": {"password"},
+		"username":   {w.username},
+		"password":   {w.password},
+	}); err != nil {
+		return "", err
+	}
+	return w.accessToken, nil
+}
+
+func (w *wallabag) grant(ctx context.Context, form url.Values) error {
+	form.Set("client_id", w.clientID)
+	form.Set("client_secret", w.clientSecret)
+
+	req, err := w.newRequest(ctx, http.MethodPost, "/oauth/v2/token", strings.NewReader(form.Encode()), "application/x-www-form-urlencoded")
+	if err != nil {
+		return err
+	}
+
+	var tok tokenResponse
+	if _, err := w.do(req, &tok); err != nil {
+		return fmt.Errorf("wallabag: %s grant: %w", form.Get("grant_type"), err)
+	}
+	if tok.AccessToken == "" {
+		return fmt.Errorf("wallabag: %s grant returned no access token", form.Get("grant_type"))
+	}
+
+	w.accessToken = tok.AccessToken
+	if tok.RefreshToken != "" {
+		w.refreshToken = tok.RefreshToken
+	}
+	w.expiresAt = time.Now().Add(time.Duration(tok.ExpiresIn) * time.Second)
+	return nil
+}
+
+func (w *wallabag) invalidate() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.accessToken = ""
+}
+
+// call sends an authorized request, renewing the token and retrying once if
+// the server rejects it.
+func (w *wallabag) call(ctx context.Context, method, path string, form url.Values, out any) error {
+	for attempt := 0; ; attempt++ {
+		token, err := w.token(ctx)
+		if err != nil {
+			return err
+		}
+
+		var req *http.Request
+		if form != nil {
+			req, err = w.newRequest(ctx, method, path, strings.NewReader(form.Encode()), "application/x-www-form-urlencoded")
+		} else {
+			req, err = w.newRequest(ctx, method, path, nil, "")
+		}
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		_, err = w.do(req, out)
+		if errors.Is(err, errUnauthorized) && attempt == 0 {
+			w.invalidate()
+			continue
+		}
+		return err
+	}
+}
+
+func (w *wallabag) find(ctx context.Context, link string) (string, error) {
+	query := url.Values{"url": {link}, "return_id": {"1"}}
+
+	// exists is the entry ID, or false/null when the URL isn't saved.
+	var resp struct {
+		Exists any `json:"exists"`
+	}
+	if err := w.call(ctx, http.MethodGet, "/api/entries/exists.json?"+query.Encode(), nil, &resp); err != nil {
+		return "", err
+	}
+	if id, ok := resp.Exists.(float64); ok {
+		return strconv.FormatInt(int64(id), 10), nil
+	}
+	return "", nil
+}
+
+// save creates an entry. Wallabag has no description field; the article is
+// fetched and stored by the server itself.
+func (w *wallabag) save(ctx context.Context, b *Bookmark) (string, error) {
+	form := url.Values{
+		"url":   {b.URL},
+		"title": {b.Title},
+		"tags":  {strings.Join(b.Tags, ",")},
+	}
+
+	var resp struct {
+		ID int64 `json:"id"`
+	}
+	if err := w.call(ctx, http.MethodPost, "/api/entries.json", form, &resp); err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(resp.ID, 10), nil
+}
+
+func (w *wallabag) remove(ctx context.Context, id string) error {
+	return w.call(ctx, http.MethodDelete, "/api/entries/"+url.PathEscape(id)+".json", nil, nil)
+}
@@ -0,0 +1,306 @@
+package slack
+
+import (
+	"bytes"
+	"cartero/internal/config"
+	"cartero/internal/platforms"
+	"cartero/internal/types"
+	"cartero/internal/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"text/template"
+	"time"
+)
+
+const (
+	apiURL = "https://slack.com/api/"
+
+	channelTypeMessage = "message"
+	channelTypeThread  = "thread"
+)
+
+// Target posts items as Block Kit messages, either to an incoming webhook or
+// with a bot token through chat.postMessage. In "thread" mode, which needs
+// the bot token, the headline is posted to the channel and the details are
+// replied in its thread.
+type Target struct {
+	name        string
+	webhookURL  string
+	botToken    string
+	channelID   string
+	channelType string
+	template    *template.Template
+	client      *http.Client
+}
+
+func New(name string, settings config.TargetSettings) (*Target, error) {
+	channelType := settings.ChannelType
+	if channelType == "" {
+		channelType = channelTypeMessage
+	}
+
+	switch {
+	case settings.BotToken != "":
+		if settings.ChannelID == "" {
+			return nil, fmt.Errorf("slack: channel_id is required with bot_token")
+		}
+	case settings.URL != "":
+		if channelType == channelTypeThread {
+			return nil, fmt.Errorf("slack: thread mode requires bot_token")
+		}
+	default:
+		return nil, fmt.Errorf("slack: url or bot_token is required")
+	}
+	if channelType != channelTypeMessage && channelType != channelTypeThread {
+		return nil, fmt.Errorf("slack: unsupported channel type: %s", channelType)
+	}
+
+	templatePath := settings.Template
+	if templatePath == "" {
+		templatePath = "templates/slack.tmpl"
+	}
+	tmpl, err := utils.LoadTemplate(templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("slack: %w", err)
+	}
+
+	return &Target{
+		name:        name,
+		webhookURL:  settings.URL,
+		botToken:    settings.BotToken,
+		channelID:   settings.ChannelID,
+		channelType: channelType,
+		template:    tmpl,
+		client:      &http.Client{Timeout: config.ParseDuration(settings.Timeout, 30*time.Second)},
+	}, nil
+}
+
+func (t *Target) Name() string {
+	return t.name
+}
+
+func (t *Target) Initialize(ctx context.Context) error {
+	return nil
+}
+
+func (t *Target) Publish(ctx context.Context, item *types.Item) (*types.PublishResult, error) {
+	msg, err := t.render(item)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.botToken == "" {
+		payload := map[string]any{
+			"text":   msg.Text(),
+			"blocks": append(msg.Headline(), msg.Details()...),
+		}
+		if err := t.postWebhook(ctx, payload); err != nil {
+			return utils.Failure(err), err
+		}
+		return &types.PublishResult{Success: true, Metadata: map[string]any{}}, nil
+	}
+
+	blocks := msg.Headline()
+	if t.channelType == channelTypeMessage {
+		blocks = append(blocks, msg.Details()...)
+	}
+
+	ts, err := t.postMessage(ctx, msg.Text(), blocks, "")
+	if err != nil {
+		return utils.Failure(err), err
+	}
+
+	metadata := map[string]any{
+		"channel": t.channelID,
+		"ts":      ts,
+	}
+
+	if details := msg.Details(); t.channelType == channelTypeThread && len(details) > 0 {
+		replyTS, err := t.postMessage(ctx, msg.Title, details, ts)
+		if err != nil {
+			// The headline is already posted; retrying would duplicate it.
+			metadata["reply_error"] = err.Error()
+		} else {
+			metadata["reply_ts"] = replyTS
+		}
+	}
+
+	return &types.PublishResult{Success: true, Metadata: metadata}, nil
+}
+
+// Update edits a message posted with the bot token. Webhook messages can't
+// be edited.
+func (t *Target) Update(ctx context.Context, item *types.Item, published map[string]any) (*types.PublishResult, error) {
+	channel, ts, err := t.messageRef(published)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := t.render(item)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := msg.Headline()
+	if t.channelType == channelTypeMessage {
+		blocks = append(blocks, msg.Details()...)
+	}
+
+	var resp apiResponse
+	if err := t.call(ctx, "chat.update", map[string]any{
+		"channel": channel,
+		"ts":      ts,
+		"text":    msg.Text(),
+		"blocks":  blocks,
+	}, &resp); err != nil {
+		return utils.Failure(err), err
+	}
+
+	return &types.PublishResult{
+		Success:  true,
+		Metadata: map[string]any{"channel": channel, "ts": ts},
+	}, nil
+}
+
+// Delete removes a message posted with the bot token, along with its thread
+// reply.
+func (t *Target) Delete(ctx context.Context, item *types.Item, published map[string]any) error {
+	channel, ts, err := t.messageRef(published)
+	if err != nil {
+		return err
+	}
+
+	if replyTS, _ := published["reply_ts"].(string); replyTS != "" {
+		var resp apiResponse
+		if err := t.call(ctx, "chat.delete", map[string]any{"channel": channel, "ts": replyTS}, &resp); err != nil {
+			return err
+		}
+	}
+
+	var resp apiResponse
+	return t.call(ctx, "chat.delete", map[string]any{"channel": channel, "ts": ts}, &resp)
+}
+
+func (t *Target) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func (t *Target) render(item *types.Item) (*Message, error) {
+	var buf bytes.Buffer
+	if err := t.template.Execute(&buf, item); err != nil {
+		return nil, fmt.Errorf("slack: template execution error: %w", err)
+	}
+
+	var msg Message
+	if err := msg.TryFrom(buf.Bytes()); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+func (t *Target) messageRef(published map[string]any) (channel, ts string, err error) {
+	if t.botToken == "" {
+		return "", "", fmt.Errorf("slack: webhook messages can't be edited or deleted")
+	}
+	ts, _ = published["ts"].(string)
+	if ts == "" {
+		return "", "", fmt.Errorf("slack: publish result has no ts")
+	}
+	channel, _ = published["channel"].(string)
+	if channel == "" {
+		channel = t.channelID
+	}
+	return channel, ts, nil
+}
+
+func (t *Target) postWebhook(ctx context.Context, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("slack: marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("slack: failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("slack: webhook request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return &platforms.RateLimitError{Platform: "slack", RetryAfter: platforms.ParseRetryAfter(resp.Header.Get("Retry-After"))}
+	}
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("slack: webhook returned status %d: %s", resp.StatusCode, string(b))
+	}
+	return nil
+}
+
+type apiResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+	TS    string `json:"ts"`
+}
+
+func (t *Target) postMessage(ctx context.Context, text string, blocks []Block, threadTS string) (string, error) {
+	payload := map[string]any{
+		"channel":      t.channelID,
+		"text":         text,
+		"blocks":       blocks,
+		"unfurl_links": false,
+	}
+	if threadTS != "" {
+		payload["thread_ts"] = threadTS
+	}
+
+	var resp apiResponse
+	if err := t.call(ctx, "chat.postMessage", payload, &resp); err != nil {
+		return "", err
+	}
+	return resp.TS, nil
+}
+
+// call invokes a Web API method. Slack reports most failures with HTTP 200
+// and ok=false, but rate limits come back as 429 with Retry-After.
+func (t *Target) call(ctx context.Context, method string, payload any, out *apiResponse) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("slack: marshal %s: %w", method, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL+method, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("slack: failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+t.botToken)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("slack: %s failed: %w", method, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return &platforms.RateLimitError{Platform: "slack", RetryAfter: platforms.ParseRetryAfter(resp.Header.Get("Retry-After"))}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack: %s returned status %d", method, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("slack: decode %s response: %w", method, err)
+	}
+	if !out.OK {
+		return fmt.Errorf("slack: %s: %s", method, out.Error)
+	}
+	return nil
+}
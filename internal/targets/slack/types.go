@@ -0,0 +1,141 @@
+package slack
+
+import (
+	strutils "cartero/internal/utils/string"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxSectionText is Slack's limit for the text of a section block.
+const maxSectionText = 3000
+
+// Message is what templates/slack.tmpl renders; Blocks turns it into Block
+// Kit so the template doesn't have to deal with mrkdwn escaping.
+type Message struct {
+	Title        string  `json:"title"`
+	URL          string  `json:"url"`
+	Score        float64 `json:"score,omitempty"`
+	Author       string  `json:"author,omitempty"`
+	Summary      string  `json:"summary,omitempty"`
+	Comments     string  `json:"comments,omitempty"`
+	CommentCount count   `json:"comment_count,omitempty"`
+	Thumbnail    string  `json:"thumbnail,omitempty"`
+	Source       string  `json:"source,omitempty"`
+}
+
+// count is a number that sources may also hand over as a string, such as
+// "42" from an HTML scrape. Anything that doesn't parse counts as 0.
+type count int
+
+func (c *count) UnmarshalJSON(data []byte) error {
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		*c = 0
+		return nil
+	}
+	f, err := n.Float64()
+	if err != nil {
+		*c = 0
+		return nil
+	}
+	*c = count(f)
+	return nil
+}
+
+type Block struct {
+	Type      string       `json:"type"`
+	Text      *TextObject  `json:"text,omitempty"`
+	Fields    []TextObject `json:"fields,omitempty"`
+	Elements  []TextObject `json:"elements,omitempty"`
+	Accessory *Accessory   `json:"accessory,omitempty"`
+}
+
+type TextObject struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type Accessory struct {
+	Type     string `json:"type"`
+	ImageURL string `json:"image_url"`
+	AltText  string `json:"alt_text"`
+}
+
+func (m *Message) TryFrom(templateOutput []byte) error {
+	if err := json.Unmarshal(templateOutput, m); err != nil {
+		return fmt.Errorf("slack: failed to unmarshal template output to Message: %w", err)
+	}
+	return nil
+}
+
+// Text is the notification fallback shown where blocks aren't rendered.
+func (m *Message) Text() string {
+	if m.URL == "" {
+		return m.Title
+	}
+	return m.Title + " " + m.URL
+}
+
+// Headline returns the blocks for the post itself: linked title with the
+// thumbnail as accessory, score and author fields, and the source line.
+func (m *Message) Headline() []Block {
+	title := escape(m.Title)
+	if m.URL != "" {
+		title = "<" + m.URL + "|" + title + ">"
+	}
+
+	section := Block{Type: "section", Text: mrkdwn("*" + title + "*")}
+	if m.Thumbnail != "" {
+		section.Accessory = &Accessory{Type: "image", ImageURL: m.Thumbnail, AltText: m.Title}
+	}
+	blocks := []Block{section}
+
+	var fields []TextObject
+	if m.Score != 0 {
+		fields = append(fields, *mrkdwn("*Score*\n" + strconv.FormatFloat(m.Score, 'f', 2, 64)))
+	}
+	if m.Author != "" {
+		fields = append(fields, *mrkdwn("*Author*\n" + escape(m.Author)))
+	}
+	if len(fields) > 0 {
+		blocks = append(blocks, Block{Type: "section", Fields: fields})
+	}
+
+	var footer []string
+	if m.Source != "" {
+		footer = append(footer, "Source: "+escape(m.Source))
+	}
+	if m.Comments != "" {
+		label := "Discussion"
+		if m.CommentCount > 0 {
+			label = fmt.Sprintf("Discussion (%d comments)", m.CommentCount)
+		}
+		footer = append(footer, "<"+m.Comments+"|"+label+">")
+	}
+	if len(footer) > 0 {
+		blocks = append(blocks, Block{Type: "context", Elements: []TextObject{*mrkdwn(strings.Join(footer, " · "))}})
+	}
+
+	return blocks
+}
+
+// Details returns the blocks that go into the thread in thread mode, or
+// below the headline otherwise.
+func (m *Message) Details() []Block {
+	if m.Summary == "" {
+		return nil
+	}
+	return []Block{{Type: "section", Text: mrkdwn(strutils.Truncate(escape(m.Summary), maxSectionText))}}
+}
+
+func mrkdwn(text string) *TextObject {
+	return &TextObject{Type: "mrkdwn", Text: text}
+}
+
+// escape replaces the characters Slack treats as control sequences in
+// mrkdwn text.
+func escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,258 @@
+package static
+
+import (
+	"bytes"
+	"cartero/internal/server/feed/handler"
+	"cartero/internal/storage"
+	"cartero/internal/utils"
+	strutils "cartero/internal/utils/string"
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const assetsDir = "assets"
+
+// page is one rendered HTML page, for the sitemap.
+type page struct {
+	url     string
+	lastmod time.Time
+}
+
+type group struct {
+	name    string
+	entries []storage.FeedEntry
+}
+
+type builder struct {
+	t       *Target
+	updated time.Time
+	files   map[string][]byte
+	pages   []page
+}
+
+// build renders the whole site in memory. Everything derives from the
+// entries, with the newest entry's time standing in for "now", so a rebuild
+// without new entries produces identical bytes and nothing gets rewritten.
+func (t *Target) build(entries []storage.FeedEntry) (map[string][]byte, error) {
+	b := &builder{t: t, files: make(map[string][]byte)}
+	for _, e := range entries {
+		if e.CreatedAt.After(b.updated) {
+			b.updated = e.CreatedAt
+		}
+	}
+
+	if err := b.listing("", t.siteName, entries); err != nil {
+		return nil, err
+	}
+
+	for _, g := range groupBy(entries, keywordsOf) {
+		if err := b.listing("keywords/"+utils.Slug(g.name)+"/", t.siteName+" · "+g.name, g.entries); err != nil {
+			return nil, err
+		}
+	}
+	for _, g := range groupBy(entries, func(e storage.FeedEntry) []string { return []string{e.Source} }) {
+		if err := b.listing("sources/"+utils.Slug(g.name)+"/", t.siteName+" · "+strutils.Readable(g.name), g.entries); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := b.feeds(entries); err != nil {
+		return nil, err
+	}
+	if err := b.sitemap(); err != nil {
+		return nil, err
+	}
+	b.files["robots.txt"] = fmt.Appendf(nil, "User-agent: *\nAllow: /\n\nSitemap: %s/sitemap.xml\n", t.siteURL)
+
+	if err := b.assets(); err != nil {
+		return nil, err
+	}
+
+	return b.files, nil
+}
+
+// listing renders the paginated pages for entries under prefix, which is
+// empty for the homepage and otherwise ends in a slash.
+func (b *builder) listing(prefix, title string, entries []storage.FeedEntry) error {
+	total := len(entries)
+	totalPages := max((total+b.t.perPage-1)/b.t.perPage, 1)
+
+	pageURL := func(n int) string {
+		if n == 1 {
+			return "/" + prefix
+		}
+		return fmt.Sprintf("/%spage/%d/", prefix, n)
+	}
+
+	for n := 1; n <= totalPages; n++ {
+		start := (n - 1) * b.t.perPage
+		pageEntries := entries[start:min(start+b.t.perPage, total)]
+
+		data := map[string]interface{}{
+			"Title":       title,
+			"Query":       "",
+			"Entries":     pageEntries,
+			"Now":         b.updated,
+			"Page":        n,
+			"TotalPages":  totalPages,
+			"HasNext":     n < totalPages,
+			"HasPrev":     n > 1,
+			"PrevURL":     pageURL(n - 1),
+			"NextURL":     pageURL(n + 1),
+			"Total":       total,
+			"BaseURL":     b.t.siteURL,
+			"Canonical":   b.t.siteURL + pageURL(n),
+			"Description": b.t.siteDescription,
+			"NoIndex":     n > 1,
+			"Static":      true,
+		}
+
+		var buf bytes.Buffer
+		if err := b.t.tmpl.Execute(&buf, data); err != nil {
+			return fmt.Errorf("static: render %s: %w", pageURL(n), err)
+		}
+		b.files[strings.TrimPrefix(pageURL(n), "/")+"index.html"] = buf.Bytes()
+
+		var lastmod time.Time
+		for _, e := range pageEntries {
+			if e.CreatedAt.After(lastmod) {
+				lastmod = e.CreatedAt
+			}
+		}
+		b.pages = append(b.pages, page{url: pageURL(n), lastmod: lastmod})
+	}
+	return nil
+}
+
+func (b *builder) feeds(entries []storage.FeedEntry) error {
+	feed := handler.BuildFeed(handler.FeedInfo{
+		Title:       b.t.siteName,
+		Link:        b.t.siteURL + "/",
+		Description: b.t.siteDescription,
+		MaxItems:    b.t.feedSize,
+	}, entries)
+	feed.Created = b.updated
+
+	rss, err := feed.ToRss()
+	if err != nil {
+		return fmt.Errorf("static: render rss: %w", err)
+	}
+	atom, err := feed.ToAtom()
+	if err != nil {
+		return fmt.Errorf("static: render atom: %w", err)
+	}
+	jsonFeed, err := feed.ToJSON()
+	if err != nil {
+		return fmt.Errorf("static: render json feed: %w", err)
+	}
+
+	b.files["feed.rss"] = []byte(rss)
+	b.files["feed.atom"] = []byte(atom)
+	b.files["feed.json"] = []byte(jsonFeed)
+	return nil
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type urlSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+func (b *builder) sitemap() error {
+	set := urlSet{XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, p := range b.pages {
+		u := sitemapURL{Loc: b.t.siteURL + p.url}
+		if !p.lastmod.IsZero() {
+			u.LastMod = p.lastmod.UTC().Format(time.RFC3339)
+		}
+		set.URLs = append(set.URLs, u)
+	}
+
+	out, err := xml.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return fmt.Errorf("static: render sitemap: %w", err)
+	}
+	b.files["sitemap.xml"] = append([]byte(xml.Header), out...)
+	return nil
+}
+
+// assets copies the feed server's static files. The service worker is also
+// served from the root, where homepage.gotmpl registers it.
+func (b *builder) assets() error {
+	err := filepath.WalkDir(assetsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(assetsDir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "sitemap.xml" || rel == "robots.txt" {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		b.files["assets/"+filepath.ToSlash(rel)] = data
+		if rel == "sw.js" {
+			b.files["sw.js"] = data
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("static: copy assets: %w", err)
+	}
+	return nil
+}
+
+// groupBy collects entries under each name key returns, keeping their
+// order. Groups come back sorted by name.
+func groupBy(entries []storage.FeedEntry, key func(storage.FeedEntry) []string) []group {
+	bySlug := make(map[string]*group)
+	for _, e := range entries {
+		for _, name := range key(e) {
+			slug := utils.Slug(name)
+			if slug == "" {
+				continue
+			}
+			g, ok := bySlug[slug]
+			if !ok {
+				g = &group{name: name}
+				bySlug[slug] = g
+			}
+			g.entries = append(g.entries, e)
+		}
+	}
+
+	groups := make([]group, 0, len(bySlug))
+	for _, g := range bySlug {
+		groups = append(groups, *g)
+	}
+	slices.SortFunc(groups, func(a, b group) int {
+		return strings.Compare(utils.Slug(a.name), utils.Slug(b.name))
+	})
+	return groups
+}
+
+func keywordsOf(e storage.FeedEntry) []string {
+	var out []string
+	for _, kw := range strings.Split(e.MatchedKeywords, ",") {
+		if kw = strings.TrimSpace(kw); kw != "" {
+			out = append(out, kw)
+		}
+	}
+	return out
+}
@@ -0,0 +1,144 @@
+package static
+
+import (
+	"cartero/internal/components"
+	"cartero/internal/config"
+	"cartero/internal/server/feed/handler"
+	"cartero/internal/storage"
+	"cartero/internal/template"
+	"cartero/internal/types"
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"log/slog"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPerPage  = 80
+	defaultMaxItems = 2000
+	defaultFeedSize = 50
+)
+
+// Target exports the items published to it as a static site: paginated
+// homepage.gotmpl pages, per-keyword and per-source listings, RSS, Atom and
+// JSON feeds and a sitemap. Publish only records the item; the site is
+// rebuilt once per cycle in Finalize and only changed files are rewritten.
+type Target struct {
+	name            string
+	entryStore      storage.EntryStore
+	outputDir       string
+	perPage         int
+	maxItems        int
+	feedSize        int
+	siteURL         string
+	siteName        string
+	siteDescription string
+	tmpl            *htmltemplate.Template
+	logger          *slog.Logger
+
+	mu     sync.Mutex
+	writer *siteWriter
+}
+
+func New(name string, settings config.TargetSettings, registry *components.Registry, logger *slog.Logger) (*Target, error) {
+	if settings.OutputDir == "" {
+		return nil, fmt.Errorf("static: output_dir is required")
+	}
+
+	funcs := handler.FuncMap()
+	maps.Copy(funcs, htmltemplate.FuncMap{
+		// Relative ages would go stale on a static page.
+		"timeAgo": func(t time.Time) string { return t.Format("Jan 2, 2006") },
+	})
+
+	templatePath := settings.Template
+	if templatePath == "" {
+		templatePath = "templates/homepage.gotmpl"
+	}
+	tmpl := &template.Template{}
+	if err := tmpl.Load(templatePath, template.HtmlTemplate, funcs); err != nil {
+		return nil, fmt.Errorf("static: %w", err)
+	}
+
+	perPage := settings.PerPage
+	if perPage <= 0 {
+		perPage = defaultPerPage
+	}
+	maxItems := settings.MaxItems
+	if maxItems <= 0 {
+		maxItems = defaultMaxItems
+	}
+	feedSize := settings.FeedSize
+	if feedSize <= 0 {
+		feedSize = defaultFeedSize
+	}
+	siteName := settings.SiteName
+	if siteName == "" {
+		siteName = "cartero"
+	}
+
+	store := registry.Get(components.StorageComponentName).(*components.StorageComponent).Store()
+
+	return &Target{
+		name:            name,
+		entryStore:      store.Entries(),
+		outputDir:       settings.OutputDir,
+		perPage:         perPage,
+		maxItems:        maxItems,
+		feedSize:        feedSize,
+		siteURL:         strings.TrimRight(settings.SiteURL, "/"),
+		siteName:        siteName,
+		siteDescription: settings.SiteDescription,
+		tmpl:            tmpl.HTMLTemplate(),
+		logger:          logger,
+		writer:          newSiteWriter(settings.OutputDir),
+	}, nil
+}
+
+func (t *Target) Name() string {
+	return t.name
+}
+
+func (t *Target) Initialize(ctx context.Context) error {
+	return nil
+}
+
+// Publish has nothing to do per item: the pipeline records the item as
+// published to this target, which is what the next build lists.
+func (t *Target) Publish(ctx context.Context, item *types.Item) (*types.PublishResult, error) {
+	return &types.PublishResult{
+		Success:  true,
+		Metadata: map[string]any{"output_dir": t.outputDir},
+	}, nil
+}
+
+// Finalize rebuilds the site from the entries published to this target.
+func (t *Target) Finalize(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entries, err := t.entryStore.ListPublishedEntries(ctx, t.name, t.maxItems)
+	if err != nil {
+		return fmt.Errorf("static: list entries: %w", err)
+	}
+
+	files, err := t.build(entries)
+	if err != nil {
+		return err
+	}
+
+	written, removed, err := t.writer.Write(files)
+	if err != nil {
+		return err
+	}
+	t.logger.Info("static: site exported", "target", t.name, "files", len(files), "written", written, "removed", removed)
+	return nil
+}
+
+func (t *Target) Shutdown(ctx context.Context) error {
+	return nil
+}
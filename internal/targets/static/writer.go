@@ -0,0 +1,100 @@
+package static
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// manifestName records what the previous build wrote, so unchanged files are
+// left alone and files that are no longer generated get removed.
+const manifestName = ".cartero-static.json"
+
+type siteWriter struct {
+	dir    string
+	hashes map[string]string
+}
+
+func newSiteWriter(dir string) *siteWriter {
+	w := &siteWriter{dir: dir, hashes: make(map[string]string)}
+	if data, err := os.ReadFile(filepath.Join(dir, manifestName)); err == nil {
+		_ = json.Unmarshal(data, &w.hashes)
+	}
+	return w
+}
+
+// Write brings the output directory in line with files, keyed by slash
+// separated path. Only new or changed files are rewritten.
+func (w *siteWriter) Write(files map[string][]byte) (written, removed int, err error) {
+	hashes := make(map[string]string, len(files))
+
+	for rel, content := range files {
+		sum := sha256.Sum256(content)
+		hash := hex.EncodeToString(sum[:])
+		hashes[rel] = hash
+
+		path := filepath.Join(w.dir, filepath.FromSlash(rel))
+		if w.hashes[rel] == hash {
+			if _, err := os.Stat(path); err == nil {
+				continue
+			}
+		}
+		if err := writeFile(path, content); err != nil {
+			return written, removed, err
+		}
+		written++
+	}
+
+	for rel := range w.hashes {
+		if _, ok := hashes[rel]; ok {
+			continue
+		}
+		if err := os.Remove(filepath.Join(w.dir, filepath.FromSlash(rel))); err != nil && !os.IsNotExist(err) {
+			return written, removed, fmt.Errorf("static: remove %s: %w", rel, err)
+		}
+		removed++
+	}
+
+	manifest, err := json.Marshal(hashes)
+	if err != nil {
+		return written, removed, fmt.Errorf("static: encode manifest: %w", err)
+	}
+	if err := writeFile(filepath.Join(w.dir, manifestName), manifest); err != nil {
+		return written, removed, err
+	}
+	w.hashes = hashes
+
+	return written, removed, nil
+}
+
+// writeFile replaces path atomically, so a sync to the host never uploads a
+// half-written page.
+func writeFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("static: create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".cartero-*")
+	if err != nil {
+		return fmt.Errorf("static: create temp file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("static: write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("static: write %s: %w", path, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("static: write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("static: write %s: %w", path, err)
+	}
+	return nil
+}
@@ -2,25 +2,77 @@ package targets
 
 import (
 	"cartero/internal/components"
+	"cartero/internal/config"
 	blueskypkg "cartero/internal/targets/bluesky"
 	discordpkg "cartero/internal/targets/discord"
+	emailpkg "cartero/internal/targets/email"
 	feedpkg "cartero/internal/targets/feed"
+	jsonlpkg "cartero/internal/targets/jsonl"
+	markdownpkg "cartero/internal/targets/markdown"
+	mastodonpkg "cartero/internal/targets/mastodon"
+	matrixpkg "cartero/internal/targets/matrix"
+	pushpkg "cartero/internal/targets/push"
+	readlaterpkg "cartero/internal/targets/readlater"
+	slackpkg "cartero/internal/targets/slack"
+	staticpkg "cartero/internal/targets/static"
 	telegrampkg "cartero/internal/targets/telegram"
+	webhookpkg "cartero/internal/targets/webhook"
 	"cartero/internal/types"
+	"log/slog"
 )
 
 func NewFeedTarget(name string, registry *components.Registry) types.Target {
 	return feedpkg.New(name, registry)
 }
 
-func NewDiscordTarget(name string, channelID, channelType string, registry *components.Registry) types.Target {
-	return discordpkg.New(name, channelID, channelType, registry)
+func NewDiscordTarget(name string, settings config.TargetSettings, registry *components.Registry, logger *slog.Logger) types.Target {
+	return discordpkg.New(name, settings, registry, logger)
 }
 
-func NewBlueskyTarget(name string, languages []string, registry *components.Registry) types.Target {
-	return blueskypkg.New(name, languages, registry)
+func NewBlueskyTarget(name string, settings config.BlueskyTargetSettings, registry *components.Registry) types.Target {
+	return blueskypkg.New(name, settings, registry)
 }
 
-func NewTelegramTarget(name string, chatID int64, registry *components.Registry) types.Target {
-	return telegrampkg.New(name, chatID, registry)
+func NewTelegramTarget(name string, settings config.TelegramTargetSettings, registry *components.Registry) types.Target {
+	return telegrampkg.New(name, settings, registry)
+}
+
+func NewWebhookTarget(name string, settings config.TargetSettings) (types.Target, error) {
+	return webhookpkg.New(name, settings)
+}
+
+func NewMastodonTarget(name string, settings config.TargetSettings, registry *components.Registry) (types.Target, error) {
+	return mastodonpkg.New(name, settings, registry)
+}
+
+func NewEmailTarget(name string, settings config.TargetSettings, registry *components.Registry, queue emailpkg.Queue, logger *slog.Logger) (types.Target, error) {
+	return emailpkg.New(name, settings, registry, queue, logger)
+}
+
+func NewSlackTarget(name string, settings config.TargetSettings) (types.Target, error) {
+	return slackpkg.New(name, settings)
+}
+
+func NewMatrixTarget(name string, settings config.TargetSettings, registry *components.Registry) (types.Target, error) {
+	return matrixpkg.New(name, settings, registry)
+}
+
+func NewPushTarget(name, service string, settings config.TargetSettings) (types.Target, error) {
+	return pushpkg.New(name, service, settings)
+}
+
+func NewMarkdownTarget(name string, settings config.TargetSettings) (types.Target, error) {
+	return markdownpkg.New(name, settings)
+}
+
+func NewStaticSiteTarget(name string, settings config.TargetSettings, registry *components.Registry, logger *slog.Logger) (types.Target, error) {
+	return staticpkg.New(name, settings, registry, logger)
+}
+
+func NewReadLaterTarget(name, service string, settings config.TargetSettings, index readlaterpkg.Index) (types.Target, error) {
+	return readlaterpkg.New(name, service, settings, index)
+}
+
+func NewJSONLTarget(name string, settings config.TargetSettings) (types.Target, error) {
+	return jsonlpkg.New(name, settings)
 }
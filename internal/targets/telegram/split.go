@@ -0,0 +1,225 @@
+package telegram
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+const (
+	// maxMessageLength and maxCaptionLength are Telegram's limits, counted
+	// in UTF-16 code units. Markup is counted too, which keeps us on the
+	// safe side of the limit on the visible text.
+	maxMessageLength = 4096
+	maxCaptionLength = 1024
+)
+
+type htmlToken struct {
+	raw     string
+	tag     string
+	isTag   bool
+	closing bool
+}
+
+// tokenizeHTML splits Telegram HTML into tags, entities and words, so a
+// split never lands inside any of them.
+func tokenizeHTML(s string) []htmlToken {
+	var tokens []htmlToken
+	for len(s) > 0 {
+		switch {
+		case s[0] == '<':
+			end := strings.IndexByte(s, '>')
+			if end < 0 {
+				tokens = append(tokens, htmlToken{raw: s})
+				return tokens
+			}
+			raw := s[:end+1]
+			name := strings.TrimPrefix(raw[1:end], "/")
+			if i := strings.IndexFunc(name, unicode.IsSpace); i >= 0 {
+				name = name[:i]
+			}
+			tokens = append(tokens, htmlToken{
+				raw:     raw,
+				tag:     strings.ToLower(name),
+				isTag:   true,
+				closing: strings.HasPrefix(raw, "</"),
+			})
+			s = s[end+1:]
+
+		case s[0] == '&':
+			end := strings.IndexByte(s, ';')
+			if end < 0 || end > 10 {
+				end = 0
+			}
+			tokens = append(tokens, htmlToken{raw: s[:end+1]})
+			s = s[end+1:]
+
+		default:
+			// A word with its trailing whitespace.
+			end := strings.IndexAny(s, "<&")
+			if end < 0 {
+				end = len(s)
+			}
+			if sp := strings.IndexFunc(s[:end], unicode.IsSpace); sp >= 0 {
+				end = sp + 1
+				for end < len(s) && s[end] == ' ' {
+					end++
+				}
+			}
+			tokens = append(tokens, htmlToken{raw: s[:end]})
+			s = s[end:]
+		}
+	}
+	return tokens
+}
+
+func textLength(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+type htmlSplitter struct {
+	limits []int
+	chunks []string
+	buf    strings.Builder
+	size   int
+	// open holds the tags open at this point; the last pending of them are
+	// not written yet, so a chunk never ends in an empty element.
+	open    []htmlToken
+	pending int
+	content bool
+}
+
+func (s *htmlSplitter) limit() int {
+	if len(s.chunks) < len(s.limits) {
+		return s.limits[len(s.chunks)]
+	}
+	return s.limits[len(s.limits)-1]
+}
+
+// closing is the markup needed to close tags.
+func closing(tags []htmlToken) string {
+	var b strings.Builder
+	for i := len(tags) - 1; i >= 0; i-- {
+		b.WriteString("</" + tags[i].tag + ">")
+	}
+	return b.String()
+}
+
+func (s *htmlSplitter) written() []htmlToken {
+	return s.open[:len(s.open)-s.pending]
+}
+
+// write adds text, writing the pending tags first.
+func (s *htmlSplitter) write(raw string) {
+	for _, t := range s.open[len(s.open)-s.pending:] {
+		s.buf.WriteString(t.raw)
+		s.size += textLength(t.raw)
+	}
+	s.pending = 0
+
+	s.buf.WriteString(raw)
+	s.size += textLength(raw)
+	s.content = true
+}
+
+// flush ends the current chunk, closing open tags, and starts the next one
+// by reopening them.
+func (s *htmlSplitter) flush() {
+	if !s.content {
+		return
+	}
+	s.chunks = append(s.chunks, strings.TrimSpace(s.buf.String()+closing(s.written())))
+
+	s.buf.Reset()
+	s.size = 0
+	for _, t := range s.written() {
+		s.buf.WriteString(t.raw)
+		s.size += textLength(t.raw)
+	}
+	s.content = false
+}
+
+// fits reports whether raw still fits in the chunk, together with the
+// pending tags and the closing markup.
+func (s *htmlSplitter) fits(raw string) bool {
+	n := s.size + textLength(raw) + textLength(closing(s.open))
+	for _, t := range s.open[len(s.open)-s.pending:] {
+		n += textLength(t.raw)
+	}
+	return n <= s.limit()
+}
+
+// splitHTML breaks Telegram HTML into chunks that each stay within a limit
+// and keep their tags balanced. The first chunk uses limits[0], the second
+// limits[1] and so on, with the last limit applying to the rest.
+func splitHTML(html string, limits ...int) []string {
+	s := &htmlSplitter{limits: limits}
+
+	for _, t := range tokenizeHTML(strings.TrimSpace(html)) {
+		if t.isTag && t.closing {
+			for i := len(s.open) - 1; i >= 0; i-- {
+				if s.open[i].tag != t.tag {
+					continue
+				}
+				if i >= len(s.open)-s.pending {
+					// Never written, so it is simply dropped.
+					s.pending--
+				} else {
+					s.buf.WriteString(t.raw)
+					s.size += textLength(t.raw)
+				}
+				s.open = append(s.open[:i], s.open[i+1:]...)
+				break
+			}
+			continue
+		}
+		if t.isTag {
+			s.open = append(s.open, t)
+			s.pending++
+			continue
+		}
+
+		if !s.fits(t.raw) {
+			s.flush()
+		}
+		// A word longer than a whole chunk is cut by runes.
+		raw := t.raw
+		for !s.fits(raw) {
+			n := 0
+			for i, r := range raw {
+				if !s.fits(raw[:i] + string(r)) {
+					break
+				}
+				n = i + len(string(r))
+			}
+			if n == 0 {
+				break
+			}
+			s.write(raw[:n])
+			raw = raw[n:]
+			s.flush()
+		}
+		if raw != "" {
+			s.write(raw)
+		}
+	}
+
+	s.flush()
+	return s.chunks
+}
+
+// trimHTML keeps the first chunk of html that fits within limit.
+func trimHTML(html string, limit int) string {
+	chunks := splitHTML(html, limit)
+	if len(chunks) == 0 {
+		return ""
+	}
+	if len(chunks) > 1 {
+		return splitHTML(html, limit-1)[0] + "…"
+	}
+	return chunks[0]
+}
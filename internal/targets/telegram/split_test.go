@@ -0,0 +1,117 @@
+package telegram
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitHTML(t *testing.T) {
+	tests := []struct {
+		name   string
+		html   string
+		limits []int
+		want   []string
+	}{
+		{
+			name:   "fits in one chunk",
+			html:   "Hello <b>world</b>",
+			limits: []int{100},
+			want:   []string{"Hello <b>world</b>"},
+		},
+		{
+			name:   "tags are closed and reopened",
+			html:   "<b>one two three four</b>",
+			limits: []int{16},
+			want:   []string{"<b>one two </b>", "<b>three </b>", "<b>four</b>"},
+		},
+		{
+			name:   "nested tags",
+			html:   "<b>bold <i>both</i></b> plain",
+			limits: []int{18},
+			want:   []string{"<b>bold </b>", "<b><i>both</i></b>", "plain"},
+		},
+		{
+			name:   "empty elements are dropped",
+			html:   "a<b></b> b",
+			limits: []int{100},
+			want:   []string{"a b"},
+		},
+		{
+			name:   "entities stay whole",
+			html:   "Tom &amp; Jerry &amp; Spike",
+			limits: []int{12},
+			want:   []string{"Tom &amp;", "Jerry &amp;", "Spike"},
+		},
+		{
+			name:   "over-long word is cut by runes",
+			html:   "abcdefghij",
+			limits: []int{4},
+			want:   []string{"abcd", "efgh", "ij"},
+		},
+		{
+			name:   "over-long word inside a link",
+			html:   `<a href="https://go.dev">abcdefghijklmnopqrstuvwxyz</a>`,
+			limits: []int{40},
+			want: []string{
+				`<a href="https://go.dev">abcdefghijk</a>`,
+				`<a href="https://go.dev">lmnopqrstuv</a>`,
+				`<a href="https://go.dev">wxyz</a>`,
+			},
+		},
+		{
+			name:   "limits count UTF-16 code units",
+			html:   "😀😀😀😀",
+			limits: []int{4},
+			want:   []string{"😀😀", "😀😀"},
+		},
+		{
+			name:   "caption then message limit",
+			html:   strings.Repeat("word ", 10),
+			limits: []int{12, 24},
+			want:   []string{"word word", "word word word word", "word word word word"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitHTML(tt.html, tt.limits...)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("splitHTML = %q, want %q", got, tt.want)
+			}
+			for i, chunk := range got {
+				limit := tt.limits[min(i, len(tt.limits)-1)]
+				if n := textLength(chunk); n > limit {
+					t.Errorf("chunk %d is %d long, over its limit of %d", i, n, limit)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitHTMLCaptionAndMessageLimits(t *testing.T) {
+	chunks := splitHTML("<b>"+strings.Repeat("word ", 1500)+"</b>", maxCaptionLength, maxMessageLength)
+	if len(chunks) < 2 {
+		t.Fatalf("got %d chunks, want the text split", len(chunks))
+	}
+	if n := textLength(chunks[0]); n > maxCaptionLength || n < maxCaptionLength-10 {
+		t.Errorf("caption is %d long, want close to %d", n, maxCaptionLength)
+	}
+	if n := textLength(chunks[1]); n > maxMessageLength || n < maxMessageLength-10 {
+		t.Errorf("message is %d long, want close to %d", n, maxMessageLength)
+	}
+	for i, chunk := range chunks {
+		if !strings.HasPrefix(chunk, "<b>") || !strings.HasSuffix(chunk, "</b>") {
+			t.Errorf("chunk %d is not balanced: %.20q…%q", i, chunk, chunk[len(chunk)-10:])
+		}
+	}
+}
+
+func TestTrimHTML(t *testing.T) {
+	if got := trimHTML("<b>one two three</b>", 12); got != "<b>one </b>…" {
+		t.Errorf("trimHTML = %q", got)
+	}
+	if got := trimHTML("<b>one</b>", 12); got != "<b>one</b>" {
+		t.Errorf("trimHTML = %q, want the text unchanged", got)
+	}
+}
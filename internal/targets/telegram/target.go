@@ -3,23 +3,31 @@ package telegram
 import (
 	"bytes"
 	"cartero/internal/components"
+	"cartero/internal/config"
 	"cartero/internal/platforms"
 	"cartero/internal/types"
 	"cartero/internal/utils"
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"text/template"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// Target posts items to a chat, or to a forum topic when threadID is set.
+// Items with an image go out as a photo with the text as caption. Text over
+// Telegram's limits is split into follow-up replies without breaking tags.
 type Target struct {
 	name     string
 	chatID   int64
+	threadID int
 	platform *platforms.TelegramPlatform
 	template *template.Template
 }
 
-func New(name string, chatID int64, registry *components.Registry) *Target {
+func New(name string, settings config.TelegramTargetSettings, registry *components.Registry) *Target {
 	platformCmp := registry.Get(components.PlatformComponentName).(*components.PlatformComponent)
 
 	tmpl, err := utils.LoadTemplate("templates/telegram.tmpl")
@@ -29,7 +37,8 @@ func New(name string, chatID int64, registry *components.Registry) *Target {
 
 	return &Target{
 		name:     name,
-		chatID:   chatID,
+		chatID:   settings.ChatID,
+		threadID: settings.ThreadID,
 		platform: platformCmp.Telegram(),
 		template: tmpl,
 	}
@@ -51,27 +60,214 @@ func (t *Target) Publish(_ context.Context, item *types.Item) (*types.PublishRes
 			Error:   err,
 		}, fmt.Errorf("telegram: template execution error: %w", err)
 	}
-
 	text := strings.TrimSpace(buf.String())
-	msg := newMessage(t.chatID, text)
+	if len(splitHTML(text, maxMessageLength)) == 0 {
+		return nil, fmt.Errorf("telegram: message is empty")
+	}
+	markup := buttons(item)
+
+	var chunks []string
+	var first tgbotapi.Message
+	var err error
+	photo := false
 
-	sent, err := t.platform.Bot().Send(msg)
+	if image := item.GetImageURL(); isWebURL(image) {
+		chunks = splitHTML(text, maxCaptionLength, maxMessageLength)
+		first, err = t.sendPhoto(image, chunks[0], markup)
+
+		// Telegram fetches the image itself and rejects URLs it can't
+		// use; the post still goes out as text then.
+		var tgErr *tgbotapi.Error
+		if errors.As(err, &tgErr) && tgErr.Code == 400 {
+			err = nil
+		} else {
+			photo = err == nil
+		}
+	}
+	if !photo && err == nil {
+		chunks = splitHTML(text, maxMessageLength)
+		first, err = t.sendText(chunks[0], markup, 0)
+	}
 	if err != nil {
-		return &types.PublishResult{
-			Success: false,
-			Error:   err,
-		}, fmt.Errorf("telegram: failed to send message: %w", err)
+		return utils.Failure(err), fmt.Errorf("telegram: failed to send message: %w", err)
+	}
+
+	// The rest of a long text follows as replies. The post already exists,
+	// so a failure here is reported without failing the publish.
+	extra := make([]int, 0, len(chunks)-1)
+	var extraErr error
+	for _, chunk := range chunks[1:] {
+		sent, err := t.sendText(chunk, nil, first.MessageID)
+		if err != nil {
+			extraErr = err
+			break
+		}
+		extra = append(extra, sent.MessageID)
+	}
+
+	metadata := map[string]any{
+		"message_id": first.MessageID,
+		"chat_id":    t.chatID,
+		"photo":      photo,
+	}
+	if len(extra) > 0 {
+		metadata["extra_message_ids"] = extra
+	}
+	if extraErr != nil {
+		metadata["error"] = fmt.Sprintf("telegram: failed to send continuation: %v", extraErr)
 	}
 
 	return &types.PublishResult{
-		Success: true,
-		Metadata: map[string]any{
-			"message_id": sent.MessageID,
-			"chat_id":    t.chatID,
-		},
+		Success:  true,
+		Metadata: metadata,
 	}, nil
 }
 
+func (t *Target) sendPhoto(image, caption string, markup *tgbotapi.InlineKeyboardMarkup) (tgbotapi.Message, error) {
+	params := t.baseParams(markup)
+	params["photo"] = image
+	params.AddNonEmpty("caption", caption)
+	return send(t.platform.Bot(), "sendPhoto", params)
+}
+
+func (t *Target) sendText(text string, markup *tgbotapi.InlineKeyboardMarkup, replyTo int) (tgbotapi.Message, error) {
+	params := t.baseParams(markup)
+	params["text"] = text
+	params.AddNonZero("reply_to_message_id", replyTo)
+	return send(t.platform.Bot(), "sendMessage", params)
+}
+
+func (t *Target) baseParams(markup *tgbotapi.InlineKeyboardMarkup) tgbotapi.Params {
+	params := tgbotapi.Params{}
+	params.AddNonZero64("chat_id", t.chatID)
+	params.AddNonZero("message_thread_id", t.threadID)
+	params["parse_mode"] = tgbotapi.ModeHTML
+	if markup != nil {
+		_ = params.AddInterface("reply_markup", markup)
+	}
+	return params
+}
+
+// Update re-renders a published post: the caption of a photo post or the
+// text of a text post, followed by any continuation replies.
+func (t *Target) Update(_ context.Context, item *types.Item, published map[string]any) (*types.PublishResult, error) {
+	chatID, messageID, err := t.messageRef(published)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := t.template.Execute(&buf, item); err != nil {
+		return nil, fmt.Errorf("telegram: template execution error: %w", err)
+	}
+	text := strings.TrimSpace(buf.String())
+	markup := buttons(item)
+	extra := extraMessageIDs(published)
+	photo, _ := published["photo"].(bool)
+
+	limit := maxMessageLength
+	if photo {
+		limit = maxCaptionLength
+	}
+	chunks := splitHTML(text, limit, maxMessageLength)
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("telegram: message is empty")
+	}
+	// Without continuation replies to spill into, the edit has to fit into
+	// the first message.
+	if len(chunks) > len(extra)+1 {
+		last := limit
+		if len(extra) > 0 {
+			last = maxMessageLength
+		}
+		chunks = append(chunks[:len(extra)], trimHTML(strings.Join(chunks[len(extra):], "\n"), last))
+	}
+
+	var edit tgbotapi.Chattable
+	if photo {
+		caption := tgbotapi.NewEditMessageCaption(chatID, messageID, chunks[0])
+		caption.ParseMode = tgbotapi.ModeHTML
+		caption.ReplyMarkup = markup
+		edit = caption
+	} else {
+		msg := tgbotapi.NewEditMessageText(chatID, messageID, chunks[0])
+		msg.ParseMode = tgbotapi.ModeHTML
+		msg.ReplyMarkup = markup
+		edit = msg
+	}
+	if err := t.request(edit); err != nil {
+		return utils.Failure(err), fmt.Errorf("telegram: failed to edit message: %w", err)
+	}
+
+	for i, id := range extra {
+		if i+1 >= len(chunks) {
+			break
+		}
+		msg := tgbotapi.NewEditMessageText(chatID, id, chunks[i+1])
+		msg.ParseMode = tgbotapi.ModeHTML
+		if err := t.request(msg); err != nil {
+			return utils.Failure(err), fmt.Errorf("telegram: failed to edit message: %w", err)
+		}
+	}
+
+	return &types.PublishResult{
+		Success:  true,
+		Metadata: published,
+	}, nil
+}
+
+// request sends an edit, treating an unchanged message as success.
+func (t *Target) request(c tgbotapi.Chattable) error {
+	if _, err := t.platform.Bot().Request(c); err != nil && !strings.Contains(err.Error(), "message is not modified") {
+		return rateLimited(err)
+	}
+	return nil
+}
+
+func (t *Target) Delete(_ context.Context, item *types.Item, published map[string]any) error {
+	chatID, messageID, err := t.messageRef(published)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range append([]int{messageID}, extraMessageIDs(published)...) {
+		if _, err := t.platform.Bot().Request(tgbotapi.NewDeleteMessage(chatID, id)); err != nil {
+			return fmt.Errorf("telegram: failed to delete message: %w", err)
+		}
+	}
+	return nil
+}
+
+// messageRef reads the chat and message IDs from a stored publish result,
+// where JSON has turned them into float64.
+func (t *Target) messageRef(published map[string]any) (int64, int, error) {
+	messageID, ok := toInt64(published["message_id"])
+	if !ok {
+		return 0, 0, fmt.Errorf("telegram: publish result has no message_id")
+	}
+
+	chatID, ok := toInt64(published["chat_id"])
+	if !ok {
+		chatID = t.chatID
+	}
+	return chatID, int(messageID), nil
+}
+
+func extraMessageIDs(published map[string]any) []int {
+	var ids []int
+	switch v := published["extra_message_ids"].(type) {
+	case []int:
+		ids = v
+	case []any:
+		for _, id := range v {
+			if n, ok := toInt64(id); ok {
+				ids = append(ids, int(n))
+			}
+		}
+	}
+	return ids
+}
+
 func (t *Target) Shutdown(_ context.Context) error {
 	return nil
 }
@@ -1,11 +1,75 @@
 package telegram
 
 import (
+	"cartero/internal/platforms"
+	"cartero/internal/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-func newMessage(chatID int64, text string) tgbotapi.MessageConfig {
-	msg := tgbotapi.NewMessage(chatID, text)
-	msg.ParseMode = tgbotapi.ModeHTML
-	return msg
+// buttons links the article and its discussion under the post. Telegram only
+// accepts http(s) URLs here.
+func buttons(item *types.Item) *tgbotapi.InlineKeyboardMarkup {
+	var row []tgbotapi.InlineKeyboardButton
+
+	link := item.GetLink().String()
+	if isWebURL(link) {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonURL("Read", link))
+	}
+	if comments := item.GetComments(); isWebURL(comments) && comments != link {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonURL("Discussion", comments))
+	}
+
+	if len(row) == 0 {
+		return nil
+	}
+	markup := tgbotapi.NewInlineKeyboardMarkup(row)
+	return &markup
+}
+
+func isWebURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
+
+// send calls a send method directly, since the library's configs have no
+// message_thread_id for forum topics.
+func send(bot *tgbotapi.BotAPI, method string, params tgbotapi.Params) (tgbotapi.Message, error) {
+	resp, err := bot.MakeRequest(method, params)
+	if err != nil {
+		return tgbotapi.Message{}, rateLimited(err)
+	}
+
+	var msg tgbotapi.Message
+	if err := json.Unmarshal(resp.Result, &msg); err != nil {
+		return tgbotapi.Message{}, fmt.Errorf("decode %s response: %w", method, err)
+	}
+	return msg, nil
+}
+
+func toInt64(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case float64:
+		return int64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// rateLimited turns Telegram's flood control error into the shared rate
+// limit error, so the wait it asks for is honoured.
+func rateLimited(err error) error {
+	var tgErr *tgbotapi.Error
+	if errors.As(err, &tgErr) && tgErr.RetryAfter > 0 {
+		return &platforms.RateLimitError{Platform: "telegram", RetryAfter: float64(tgErr.RetryAfter)}
+	}
+	return err
 }
@@ -0,0 +1,146 @@
+package webhook
+
+import (
+	"bytes"
+	"cartero/internal/config"
+	"cartero/internal/platforms"
+	"cartero/internal/types"
+	"cartero/internal/utils"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"text/template"
+	"time"
+)
+
+const defaultSignatureHeader = "X-Cartero-Signature-256"
+
+type Target struct {
+	name            string
+	url             string
+	method          string
+	headers         map[string]string
+	secret          []byte
+	signatureHeader string
+	template        *template.Template
+	client          *http.Client
+}
+
+func New(name string, settings config.TargetSettings) (*Target, error) {
+	if settings.URL == "" {
+		return nil, fmt.Errorf("webhook: url is required")
+	}
+
+	templatePath := settings.Template
+	if templatePath == "" {
+		templatePath = "templates/webhook.tmpl"
+	}
+	tmpl, err := utils.LoadTemplate(templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: %w", err)
+	}
+
+	method := settings.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	signatureHeader := settings.SignatureHeader
+	if signatureHeader == "" {
+		signatureHeader = defaultSignatureHeader
+	}
+
+	return &Target{
+		name:            name,
+		url:             settings.URL,
+		method:          method,
+		headers:         settings.Headers,
+		secret:          []byte(settings.Secret),
+		signatureHeader: signatureHeader,
+		template:        tmpl,
+		client:          &http.Client{Timeout: config.ParseDuration(settings.Timeout, 30*time.Second)},
+	}, nil
+}
+
+func (t *Target) Name() string {
+	return t.name
+}
+
+func (t *Target) Initialize(ctx context.Context) error {
+	return nil
+}
+
+func (t *Target) Publish(ctx context.Context, item *types.Item) (*types.PublishResult, error) {
+	var buf bytes.Buffer
+	if err := t.template.Execute(&buf, item); err != nil {
+		return nil, fmt.Errorf("webhook: template execution error: %w", err)
+	}
+
+	body := bytes.TrimSpace(buf.Bytes())
+	if !json.Valid(body) {
+		return nil, fmt.Errorf("webhook: template output is not valid JSON")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, t.method, t.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("webhook: failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	if len(t.secret) > 0 {
+		req.Header.Set(t.signatureHeader, "sha256="+Sign(t.secret, body))
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return &types.PublishResult{Success: false, Error: err}, fmt.Errorf("webhook: request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		err := fmt.Errorf("webhook: rate limited")
+		return &types.PublishResult{
+			Success: false,
+			Error:   err,
+			Metadata: map[string]any{
+				"status_code": resp.StatusCode,
+				"retry_after": platforms.ParseRetryAfter(resp.Header.Get("Retry-After")),
+			},
+		}, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err := fmt.Errorf("webhook: unexpected status code: %d", resp.StatusCode)
+		return &types.PublishResult{
+			Success:  false,
+			Error:    err,
+			Metadata: map[string]any{"status_code": resp.StatusCode},
+		}, err
+	}
+
+	return &types.PublishResult{
+		Success: true,
+		Metadata: map[string]any{
+			"status_code": resp.StatusCode,
+		},
+	}, nil
+}
+
+func (t *Target) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of body, as sent in the signature header.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -7,6 +7,7 @@ import (
 	strutils "cartero/internal/utils/string"
 	"context"
 	"log/slog"
+	"maps"
 	"net/url"
 	"sync"
 	"time"
@@ -14,6 +15,11 @@ import (
 
 const scoreKey = "_score"
 
+// KeepTextKey is the metadata flag of sources that bring the full text
+// along, such as READMEs or abstracts. ExtractText keeps that text instead
+// of scraping the page, as long as it meets min_content_length.
+const KeepTextKey = "_keep_text"
+
 type Item struct {
 	ID              string
 	Title           string
@@ -27,6 +33,10 @@ type Item struct {
 	Timestamp       time.Time
 	Embedding       [][]float32 `json:"-"`
 	mu              sync.RWMutex
+
+	// rateLimits are the keys of the rate limit slots reserved for this
+	// delivery. They are not stored or encoded with the item.
+	rateLimits []string
 }
 
 type Article struct {
@@ -63,6 +73,13 @@ func (i *Item) GetTimestamp() time.Time {
 	return i.Timestamp
 }
 
+func (i *Item) GetRoute() string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return i.Route
+}
+
 func (i *Item) GetArticle() *Article {
 	i.mu.RLock()
 	defer i.mu.RUnlock()
@@ -137,6 +154,12 @@ func (i *Item) GetEmbedding() [][]float32 {
 	return i.Embedding
 }
 
+func (i *Item) GetMetadata() map[string]any {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return maps.Clone(i.Metadata)
+}
+
 func (i *Item) metaString(key string) string {
 	if i.Metadata == nil {
 		return ""
@@ -167,6 +190,20 @@ func (i *Item) GetDescription() string {
 	return i.metaString("description")
 }
 
+func (i *Item) GetSummary() string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return i.metaString("summary")
+}
+
+func (i *Item) GetComments() string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return i.metaString("comments")
+}
+
 func (i *Item) GetFeedContent() string {
 	i.mu.RLock()
 	defer i.mu.RUnlock()
@@ -177,6 +214,14 @@ func (i *Item) GetFeedContent() string {
 	return i.metaString("summary")
 }
 
+func (i *Item) KeepsText() bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	keep, _ := i.Metadata[KeepTextKey].(bool)
+	return keep
+}
+
 func (i *Item) GetAuthor() string {
 	i.mu.RLock()
 	defer i.mu.RUnlock()
@@ -200,6 +245,31 @@ func (i *Item) SetEmbedding(v [][]float32) {
 	i.Embedding = v
 }
 
+func (i *Item) Clone() *Item {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var article *Article
+	if i.TextContent != nil {
+		a := *i.TextContent
+		article = &a
+	}
+
+	return &Item{
+		ID:              i.ID,
+		Title:           i.Title,
+		URL:             i.URL,
+		Content:         i.Content,
+		Metadata:        maps.Clone(i.Metadata),
+		Source:          i.Source,
+		Route:           i.Route,
+		TextContent:     article,
+		MatchedKeywords: i.MatchedKeywords,
+		Timestamp:       i.Timestamp,
+		Embedding:       i.Embedding,
+	}
+}
+
 func (i *Item) SetScore(s float64) {
 	i.AddMetadata(scoreKey, s)
 }
@@ -211,6 +281,23 @@ func (i *Item) GetScore() float64 {
 	return 0
 }
 
+// AddRateLimit records that a rate_limit processor reserved a slot under key
+// for the item, so the slot can be released if delivery fails.
+func (i *Item) AddRateLimit(key string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.rateLimits = append(i.rateLimits, key)
+}
+
+// TakeRateLimits returns the keys AddRateLimit recorded and forgets them.
+func (i *Item) TakeRateLimits() []string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	keys := i.rateLimits
+	i.rateLimits = nil
+	return keys
+}
+
 type PublishResult struct {
 	Success  bool
 	Error    error
@@ -231,6 +318,41 @@ type Target interface {
 	Shutdown(ctx context.Context) error
 }
 
+// Updater is implemented by targets that can edit a post they published.
+// published holds the metadata stored from the original PublishResult.
+type Updater interface {
+	Update(ctx context.Context, item *Item, published map[string]any) (*PublishResult, error)
+}
+
+// Deleter is implemented by targets that can remove a post they published.
+type Deleter interface {
+	Delete(ctx context.Context, item *Item, published map[string]any) error
+}
+
+// Rechecker is implemented by sources that can look up items they emitted
+// earlier. Recheck refreshes items in place from upstream and returns the
+// IDs of items whose title changed and of items removed upstream.
+type Rechecker interface {
+	Recheck(ctx context.Context, items []*Item) (changed, removed []string, err error)
+}
+
+// Batcher is implemented by targets that collect items and deliver them
+// later in one go, such as digests. Publish only queues the item, so the
+// pipeline doesn't mark it published; the target does that itself once the
+// batch is accepted. Queued reports whether an item is waiting in a batch.
+// FlushDue sends the batch if its scheduled time passed since the last one
+// was sent, for run-once mode where the target's own schedule never fires.
+type Batcher interface {
+	Queued(ctx context.Context, itemID string) bool
+	FlushDue(ctx context.Context) error
+}
+
+// Finalizer is implemented by targets that do work once per publish cycle,
+// after the cycle's items were delivered to them, such as rebuilding pages.
+type Finalizer interface {
+	Finalize(ctx context.Context) error
+}
+
 type Queue interface {
 	Close() error
 }
@@ -244,6 +366,14 @@ type EmbedCache interface {
 	Set(ctx context.Context, hash string, embedding [][]float32)
 }
 
+// RateLimiter hands out slots in a sliding window. Reserve takes slots for
+// the leading ids and returns how many it took and, when some ids got none,
+// when the next slot frees up.
+type RateLimiter interface {
+	Reserve(ctx context.Context, key string, ids []string, limit int, window time.Duration) (int, time.Time, error)
+	Release(ctx context.Context, key string, ids ...string) error
+}
+
 type StateAccessor interface {
 	GetConfig() *config.Config
 	GetStorage() storage.StorageInterface
@@ -253,4 +383,5 @@ type StateAccessor interface {
 	GetQueue() Queue
 	GetBlocklist() Blocklist
 	GetEmbedCache() EmbedCache
+	GetRateLimiter() RateLimiter
 }
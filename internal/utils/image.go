@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+)
+
+// Image is an image downloaded to be uploaded to a target.
+type Image struct {
+	Data        []byte
+	ContentType string
+	Filename    string
+}
+
+// FetchImage downloads imageURL with client, failing if the body is larger
+// than maxSize bytes. The content type falls back to sniffing the data and
+// the filename to the last element of the URL path.
+func FetchImage(ctx context.Context, client *http.Client, imageURL string, maxSize int64) (*Image, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "image/avif,image/webp,image/apng,image/*,*/*;q=0.8")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch image: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("image too large")
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	filename := path.Base(req.URL.Path)
+	if filename == "" || filename == "/" || filename == "." {
+		filename = "image"
+	}
+
+	return &Image{Data: data, ContentType: contentType, Filename: filename}, nil
+}
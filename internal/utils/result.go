@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"cartero/internal/platforms"
+	"cartero/internal/types"
+	"errors"
+)
+
+// Failure is the result of a publish that failed with err. When err is a
+// rate limit, the wait is passed on as retry_after for the retry loop and
+// the outbox to honour.
+func Failure(err error) *types.PublishResult {
+	result := &types.PublishResult{Success: false, Error: err}
+
+	var rateLimit *platforms.RateLimitError
+	if errors.As(err, &rateLimit) && rateLimit.RetryAfter > 0 {
+		result.Metadata = map[string]any{"retry_after": rateLimit.RetryAfter}
+	}
+	return result
+}
@@ -9,6 +9,10 @@ import (
 	texttemplate "text/template"
 	"time"
 	"unicode"
+
+	strutils "cartero/internal/utils/string"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 // Template wraps either text/template or html/template
@@ -121,6 +125,7 @@ func LoadTemplate(path string) (*texttemplate.Template, error) {
 	funcMap := texttemplate.FuncMap{
 		"json":    ToJSON,
 		"hashtag": Hashtag,
+		"slug":    Slug,
 	}
 
 	tmpl, err := texttemplate.New("template").Funcs(funcMap).Parse(string(data))
@@ -152,6 +157,28 @@ func Hashtag(s string) string {
 	return b.String()
 }
 
+// Slug turns s into a lowercase, dash-separated name that is safe to use
+// in file names and URLs. Accents are dropped and the result is capped at
+// 80 bytes.
+func Slug(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range norm.NFD.String(strings.ToLower(s)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		default:
+			dash = true
+		}
+	}
+	return strings.TrimRight(strutils.Truncate(b.String(), 80), "-")
+}
+
 // ToJSON converts a value to a JSON string
 func ToJSON(v interface{}) string {
 	b, err := json.Marshal(v)